@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/satoruhiga/wtree/internal/git"
+	"github.com/satoruhiga/wtree/internal/id"
+	"github.com/satoruhiga/wtree/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt [path]",
+	Short: "Register existing git worktrees with wtree",
+	Long: `Register worktrees created with plain 'git worktree add' (or before
+wtree was used) so they show up in 'wtree ls'. Each adopted worktree gets a
+generated ID. The creation time is taken from the worktree's git admin directory.
+
+Adopted branches were not created by wtree, so 'wtree rm' keeps them.
+Detached and bare worktrees are skipped.
+
+Examples:
+  wtree adopt ../feature-x   # Adopt a single worktree
+  wtree adopt --all          # Adopt every untracked worktree`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runAdopt,
+}
+
+var adoptAll bool
+
+func init() {
+	adoptCmd.Flags().BoolVarP(&adoptAll, "all", "a", false, "Adopt all untracked worktrees")
+	rootCmd.AddCommand(adoptCmd)
+}
+
+func runAdopt(cmd *cobra.Command, args []string) error {
+	if adoptAll == (len(args) == 1) {
+		return fmt.Errorf("specify either a worktree path or --all")
+	}
+
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return err
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	gray := color.New(color.FgHiBlack).SprintFunc()
+
+	// Register sessions
+	store := session.NewStore(repoRoot)
+	var adopted []*session.Session
+	err = store.Update(func(s *session.Store) error {
+		found := false
+		for _, wt := range worktrees {
+			if len(args) == 1 && !git.SamePath(args[0], wt.Path) {
+				continue
+			}
+			found = true
+
+			if reason := adoptSkipReason(repoRoot, s, wt); reason != "" {
+				if len(args) == 1 {
+					return fmt.Errorf("cannot adopt %s: %s", wt.Path, reason)
+				}
+				continue
+			}
+
+			sess, err := newAdoptedSession(repoRoot, s, wt)
+			if err != nil {
+				return err
+			}
+			s.Add(sess)
+			adopted = append(adopted, sess)
+		}
+
+		if len(args) == 1 && !found {
+			return fmt.Errorf("not a worktree of this repository: %s", args[0])
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(adopted) == 0 {
+		fmt.Println("No untracked worktrees found.")
+		return nil
+	}
+	for _, sess := range adopted {
+		fmt.Printf("%s Adopted %s %s\n", green("✓"), sess.ID, gray(sess.Branch+"  "+sess.Path))
+	}
+	return nil
+}
+
+// adoptSkipReason returns why a worktree cannot be adopted, or "" if it can
+func adoptSkipReason(repoRoot string, store *session.Store, wt git.WorktreeInfo) string {
+	switch {
+	case git.SamePath(wt.Path, repoRoot):
+		return "it is the main worktree"
+	case wt.Bare:
+		return "it is bare"
+	case wt.Prunable:
+		return "its directory no longer exists"
+	case wt.Detached || wt.Branch == "":
+		return "it has a detached HEAD"
+	}
+	for _, sess := range store.All() {
+		if git.SamePath(sess.AbsPath, wt.Path) {
+			return "it is already tracked as " + sess.ID
+		}
+	}
+	return ""
+}
+
+// newAdoptedSession builds a session for an existing worktree
+func newAdoptedSession(repoRoot string, store *session.Store, wt git.WorktreeInfo) (*session.Session, error) {
+	// Generate an unused ID
+	var newID string
+	for {
+		generated, err := id.Generate()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate ID: %w", err)
+		}
+		if _, exists := store.Get(generated); !exists {
+			newID = generated
+			break
+		}
+	}
+
+	absPath := filepath.FromSlash(wt.Path)
+	relPath, err := filepath.Rel(repoRoot, absPath)
+	if err != nil {
+		relPath = absPath
+	}
+
+	sess := session.NewSession(newID, wt.Branch, relPath, absPath)
+	sess.ExistingBranch = true
+	if createdAt, ok := worktreeCreatedAt(absPath); ok {
+		sess.CreatedAt = createdAt
+	}
+	return sess, nil
+}
+
+// worktreeCreatedAt estimates when a worktree was created from its admin
+// directory. The commondir file is written once by 'git worktree add'.
+func worktreeCreatedAt(worktreePath string) (time.Time, bool) {
+	adminDir, err := git.WorktreeAdminDir(worktreePath)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	for _, name := range []string{"commondir", "gitdir", ""} {
+		if info, err := os.Stat(filepath.Join(adminDir, name)); err == nil {
+			return info.ModTime(), true
+		}
+	}
+	return time.Time{}, false
+}
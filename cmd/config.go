@@ -0,0 +1,444 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/satoruhiga/wtree/internal/config"
+	"github.com/satoruhiga/wtree/internal/git"
+	"github.com/satoruhiga/wtree/internal/terminal"
+	"github.com/satoruhiga/wtree/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show, change and validate the configuration",
+	Long: `Configuration is merged from, in order of increasing precedence:
+
+  ~/.config/wtree/config.toml   Personal defaults for all repositories
+  .wtree/config.toml            Repository settings
+  .wtree/config.local.toml      Personal overrides for this checkout (uncommitted)
+  WTREE_<SECTION>_<KEY>         Environment, e.g. WTREE_TERMINAL_MODE=pane
+
+Later layers override earlier ones key by key; lists are replaced.
+
+set, unset and edit change .wtree/config.toml, or the personal files with
+--local or --global. Comments in the file are kept.
+
+Examples:
+  wtree config show --origin
+  wtree config get terminal.mode
+  wtree config set --local terminal.mode pane
+  wtree config set merge.verify '["go vet ./...", "go test ./..."]'
+  wtree config unset --global terminal.exec
+  wtree config edit --local
+  wtree config validate`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration",
+	Long: `Print the effective configuration after merging all layers.
+
+Examples:
+  wtree config show
+  wtree config show --origin   # Also show which file each value came from
+  wtree config show --profile backend --origin`,
+	Args: cobra.NoArgs,
+	RunE: runConfigShow,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the effective value of a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a value in a config file",
+	Long: `Set a value in .wtree/config.toml (or --local / --global), keeping comments.
+
+Lists take a TOML array or a single item; booleans take true or false.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runConfigSet,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a value from a config file",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigUnset,
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open a config file in $EDITOR",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigEdit,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration for unknown keys and invalid values",
+	Long: `Check all config layers for unknown keys (usually typos), invalid values
+for terminal.mode, terminal.backend, merge.strategy and the [prune] durations,
+and a base_branch that doesn't exist. Exits with a non-zero status if any
+problem is found.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigValidate,
+}
+
+var (
+	configShowOrigin  bool
+	configShowProfile string
+	configLocal       bool
+	configGlobal      bool
+)
+
+func init() {
+	configShowCmd.Flags().BoolVar(&configShowOrigin, "origin", false, "Show where each value came from")
+	configShowCmd.Flags().StringVar(&configShowProfile, "profile", "", "Apply the settings of a [profile.<name>] section")
+	for _, cmd := range []*cobra.Command{configSetCmd, configUnsetCmd, configEditCmd} {
+		cmd.Flags().BoolVar(&configLocal, "local", false, "Use .wtree/config.local.toml")
+		cmd.Flags().BoolVar(&configGlobal, "global", false, "Use ~/.config/wtree/config.toml")
+		cmd.MarkFlagsMutuallyExclusive("local", "global")
+	}
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	// Load configuration
+	cfg, origins, err := config.LoadWithOrigins(repoRoot)
+	if err != nil {
+		return err
+	}
+	if configShowProfile != "" {
+		profileCfg, err := cfg.WithProfile(configShowProfile)
+		if err != nil {
+			return err
+		}
+		for _, key := range cfg.ProfileKeys(configShowProfile) {
+			origins[key] = origins["profile."+configShowProfile+"."+key] + " [profile." + configShowProfile + "]"
+		}
+		cfg = profileCfg
+	}
+
+	gray := color.New(color.FgHiBlack).SprintFunc()
+
+	// Origins are aligned within each section
+	type line struct{ section, text, origin string }
+	var lines []line
+	width := make(map[string]int)
+	for _, key := range config.Keys() {
+		section, name, _ := strings.Cut(key, ".")
+		value, _ := cfg.Value(key)
+		origin := "default"
+		if o, ok := origins[key]; ok {
+			origin = config.DisplayPath(repoRoot, o)
+		}
+		l := line{section: section, text: name + " = " + value, origin: origin}
+		width[section] = max(width[section], len(l.text))
+		lines = append(lines, l)
+	}
+
+	section := ""
+	for _, l := range lines {
+		if l.section != section {
+			if section != "" {
+				fmt.Println()
+			}
+			section = l.section
+			fmt.Printf("[%s]\n", section)
+		}
+		if configShowOrigin {
+			fmt.Printf("%-*s  %s\n", width[l.section], l.text, gray("# "+l.origin))
+		} else {
+			fmt.Println(l.text)
+		}
+	}
+	return nil
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	key := args[0]
+	if err := config.CheckKey(key); err != nil {
+		return err
+	}
+
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	// Load configuration
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	value, _ := cfg.Value(key)
+	fmt.Println(unquote(value))
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	key, raw := args[0], args[1]
+	if err := config.CheckKey(key); err != nil {
+		return err
+	}
+	value, err := config.ParseValue(key, raw)
+	if err != nil {
+		return err
+	}
+	// base_branch in the user config applies to other repositories too
+	if key != "worktree.base_branch" || !configGlobal {
+		if err := checkSetting(key, raw); err != nil {
+			return err
+		}
+	}
+
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	path, err := configTarget(repoRoot)
+	if err != nil {
+		return err
+	}
+	if err := config.SetInFile(path, key, value); err != nil {
+		return err
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	fmt.Printf("%s Set %s = %s in %s\n", green("✓"), key, value, config.DisplayPath(repoRoot, path))
+	warnOverridden(repoRoot, key, path)
+	return nil
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) error {
+	key := args[0]
+	if err := config.CheckKey(key); err != nil {
+		return err
+	}
+
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	path, err := configTarget(repoRoot)
+	if err != nil {
+		return err
+	}
+	removed, err := config.UnsetInFile(path, key)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		fmt.Printf("%s is not set in %s\n", key, config.DisplayPath(repoRoot, path))
+		return nil
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	fmt.Printf("%s Removed %s from %s\n", green("✓"), key, config.DisplayPath(repoRoot, path))
+	return nil
+}
+
+func runConfigEdit(cmd *cobra.Command, args []string) error {
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	path, err := configTarget(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	// Start a missing repository config from the commented template, like init
+	if _, err := os.Stat(path); os.IsNotExist(err) && path == config.RepoPath(repoRoot) {
+		baseBranch, err := git.GetDefaultBranch()
+		if err != nil {
+			baseBranch = "main"
+		}
+		if err := writeConfigFile(path, config.ConfigTemplate(baseBranch)); err != nil {
+			return err
+		}
+	}
+
+	if err := ui.EditFile(path); err != nil {
+		return err
+	}
+
+	// Report problems, but keep the edit
+	issues, err := configIssues(repoRoot)
+	if err != nil {
+		return err
+	}
+	yellow := color.New(color.FgYellow).SprintFunc()
+	for _, issue := range issues {
+		fmt.Printf("%s %s\n", yellow("Warning:"), issue)
+	}
+	return nil
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	issues, err := configIssues(repoRoot)
+	if err != nil {
+		return err
+	}
+	if len(issues) > 0 {
+		red := color.New(color.FgRed).SprintFunc()
+		for _, issue := range issues {
+			fmt.Printf("%s %s\n", red("✗"), issue)
+		}
+		exitWithError("found %d problem(s) in the configuration", len(issues))
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	fmt.Printf("%s Configuration is valid\n", green("✓"))
+	return nil
+}
+
+// configIssues returns unknown keys and invalid values in all config layers
+func configIssues(repoRoot string) ([]config.Issue, error) {
+	cfg, origins, issues, err := config.Check(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range config.Keys() {
+		value, _ := cfg.Value(key)
+		if err := checkSetting(key, unquote(value)); err != nil {
+			source := "default"
+			if origin, ok := origins[key]; ok {
+				source = config.DisplayPath(repoRoot, origin)
+			}
+			issues = append(issues, config.Issue{Source: source, Key: key, Message: err.Error()})
+		}
+	}
+
+	// Profiles only set some keys; check those
+	for _, name := range cfg.ProfileNames() {
+		profileCfg, _ := cfg.WithProfile(name)
+		for _, key := range cfg.ProfileKeys(name) {
+			value, _ := profileCfg.Value(key)
+			if err := checkSetting(key, unquote(value)); err != nil {
+				profileKey := "profile." + name + "." + key
+				source := config.DisplayPath(repoRoot, origins[profileKey])
+				issues = append(issues, config.Issue{Source: source, Key: profileKey, Message: err.Error()})
+			}
+		}
+	}
+	return issues, nil
+}
+
+// checkSetting validates settings that take a fixed set of values or name
+// something that must exist
+func checkSetting(key, value string) error {
+	switch key {
+	case "worktree.base_branch":
+		if !git.BranchExists(value) {
+			return fmt.Errorf("branch not found: %s", value)
+		}
+	case "terminal.backend":
+		if value != terminal.BackendAuto {
+			_, err := terminal.Get(value)
+			return err
+		}
+	case "terminal.mode":
+		_, err := terminal.ParseMode(value)
+		return err
+	case "merge.strategy":
+		_, err := git.ParseMergeStrategy(value)
+		return err
+	case "prune.max_age", "prune.remove_clean_after":
+		if value != "" {
+			_, err := config.ParseDuration(value)
+			return err
+		}
+	}
+	return nil
+}
+
+// configTarget returns the config file set, unset and edit change
+func configTarget(repoRoot string) (string, error) {
+	if configGlobal {
+		path := config.UserPath()
+		if path == "" {
+			return "", fmt.Errorf("cannot determine the user config directory")
+		}
+		return path, nil
+	}
+
+	// Keep the local files next to the repository config out of git
+	if err := config.WriteIgnoreFile(repoRoot); err != nil {
+		return "", err
+	}
+	if configLocal {
+		return config.LocalPath(repoRoot), nil
+	}
+	return config.RepoPath(repoRoot), nil
+}
+
+// warnOverridden warns when a value just written to path is overridden by a
+// later config layer
+func warnOverridden(repoRoot, key, path string) {
+	_, origins, _, err := config.Check(repoRoot)
+	if err != nil {
+		return
+	}
+	if origin, ok := origins[key]; ok && origin != path {
+		yellow := color.New(color.FgYellow).SprintFunc()
+		fmt.Printf("%s %s is overridden by %s\n", yellow("Warning:"), key, config.DisplayPath(repoRoot, origin))
+	}
+}
+
+// writeConfigFile creates a config file and its directory
+func writeConfigFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// unquote returns the contents of a TOML string value, and other values as is
+func unquote(value string) string {
+	if s, err := strconv.Unquote(value); err == nil {
+		return s
+	}
+	return value
+}
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/satoruhiga/wtree/internal/config"
+	"github.com/satoruhiga/wtree/internal/git"
+	"github.com/satoruhiga/wtree/internal/session"
+	"github.com/satoruhiga/wtree/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var conflictsCmd = &cobra.Command{
+	Use:   "conflicts",
+	Short: "Predict merge conflicts for all worktrees",
+	Long: `Test every worktree branch against the base branch with an in-memory
+merge ('git merge-tree --write-tree', git 2.38+). No checkout is touched.
+
+With --pairwise, every pair of branches is also tested, and the suggested
+merge order puts branches that conflict with fewer others first.
+
+Examples:
+  wtree conflicts             # Test each branch against the base branch
+  wtree conflicts --pairwise  # Also test branches against each other`,
+	Args: cobra.NoArgs,
+	RunE: runConflicts,
+}
+
+var conflictsPairwise bool
+
+func init() {
+	conflictsCmd.Flags().BoolVarP(&conflictsPairwise, "pairwise", "p", false, "Also test each pair of branches")
+	rootCmd.AddCommand(conflictsCmd)
+}
+
+// conflictCandidate is a branch waiting to be merged
+type conflictCandidate struct {
+	sess          *session.Session
+	baseBranch    string // From the session's profile
+	baseConflicts []string
+	// peers maps another candidate's ID to the paths both branches change
+	peers map[string][]string
+}
+
+func runConflicts(cmd *cobra.Command, args []string) error {
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	// Load configuration
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	// Load sessions
+	store := session.NewStore(repoRoot)
+	if err := store.Load(); err != nil {
+		return err
+	}
+
+	// Collect branches with unmerged commits
+	sessions := store.All()
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+
+	var candidates []*conflictCandidate
+	for _, sess := range sessions {
+		baseBranch := profileConfig(cfg, sess).Worktree.BaseBranch
+		if !git.BranchExists(sess.Branch) || git.IsAncestor(sess.Branch, baseBranch) {
+			continue
+		}
+		paths, err := git.MergeTreeConflicts(baseBranch, sess.Branch)
+		if err != nil {
+			return err
+		}
+		candidates = append(candidates, &conflictCandidate{
+			sess:          sess,
+			baseBranch:    baseBranch,
+			baseConflicts: paths,
+			peers:         make(map[string][]string),
+		})
+	}
+
+	if len(candidates) == 0 {
+		fmt.Println("No unmerged worktrees found.")
+		return nil
+	}
+
+	// Test each pair of branches
+	if conflictsPairwise {
+		for i, a := range candidates {
+			for _, b := range candidates[i+1:] {
+				paths, err := git.MergeTreeConflicts(a.sess.Branch, b.sess.Branch)
+				if err != nil {
+					return err
+				}
+				if len(paths) > 0 {
+					a.peers[b.sess.ID] = paths
+					b.peers[a.sess.ID] = paths
+				}
+			}
+		}
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	// Conflicts with the base branch, named in the header unless profiles differ
+	baseHeader := strings.ToUpper(candidates[0].baseBranch)
+	for _, c := range candidates {
+		if c.baseBranch != candidates[0].baseBranch {
+			baseHeader = "BASE"
+		}
+	}
+	headers := []string{"ID", "NAME", "BRANCH", baseHeader}
+	if conflictsPairwise {
+		headers = append(headers, "PEERS")
+	}
+	var rows [][]string
+	for _, c := range candidates {
+		baseStr := green("clean")
+		if len(c.baseConflicts) > 0 {
+			baseStr = red(fmt.Sprintf("%d conflict%s", len(c.baseConflicts), plural(len(c.baseConflicts))))
+		}
+		if baseHeader == "BASE" {
+			baseStr = c.baseBranch + ": " + baseStr
+		}
+		row := []string{c.sess.ID, c.sess.Name, c.sess.Branch, baseStr}
+		if conflictsPairwise {
+			peerStr := green("clean")
+			if len(c.peers) > 0 {
+				peerStr = yellow(fmt.Sprintf("conflicts with %d", len(c.peers)))
+			}
+			row = append(row, peerStr)
+		}
+		rows = append(rows, row)
+	}
+	ui.PrintTable(headers, rows)
+
+	// Conflicting paths
+	for _, c := range candidates {
+		if len(c.baseConflicts) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s conflicts with %s:\n", c.sess.ID, c.baseBranch)
+		for _, path := range c.baseConflicts {
+			fmt.Printf("  %s\n", red(path))
+		}
+	}
+	if conflictsPairwise {
+		for i, a := range candidates {
+			for _, b := range candidates[i+1:] {
+				paths, ok := a.peers[b.sess.ID]
+				if !ok {
+					continue
+				}
+				fmt.Printf("\n%s conflicts with %s:\n", a.sess.ID, b.sess.ID)
+				for _, path := range paths {
+					fmt.Printf("  %s\n", yellow(path))
+				}
+			}
+		}
+	}
+
+	// Suggested merge order
+	fmt.Println("\nSuggested merge order:")
+	merged := make(map[string]bool)
+	for i, c := range suggestMergeOrder(candidates) {
+		var notes []string
+		if len(c.baseConflicts) > 0 {
+			notes = append(notes, "conflicts with "+c.baseBranch)
+		}
+		var after []string
+		for id := range c.peers {
+			if merged[id] {
+				after = append(after, id)
+			}
+		}
+		if len(after) > 0 {
+			sort.Strings(after)
+			notes = append(notes, "rebase after merging "+strings.Join(after, ", "))
+		}
+		merged[c.sess.ID] = true
+
+		line := fmt.Sprintf("  %d. %s", i+1, c.sess.ID)
+		if c.sess.Name != "" {
+			line += " (" + c.sess.Name + ")"
+		}
+		if len(notes) > 0 {
+			line += "  " + yellow(strings.Join(notes, "; "))
+		}
+		fmt.Println(line)
+	}
+
+	return nil
+}
+
+// suggestMergeOrder orders candidates so that branches merging cleanly into
+// base come first, then greedily picks the branch that conflicts with the
+// fewest remaining branches, so later merges see as few conflicts as possible
+func suggestMergeOrder(candidates []*conflictCandidate) []*conflictCandidate {
+	remaining := make([]*conflictCandidate, len(candidates))
+	copy(remaining, candidates)
+
+	var order []*conflictCandidate
+	for len(remaining) > 0 {
+		remainingIDs := make(map[string]bool)
+		for _, c := range remaining {
+			remainingIDs[c.sess.ID] = true
+		}
+		openConflicts := func(c *conflictCandidate) int {
+			n := 0
+			for id := range c.peers {
+				if remainingIDs[id] {
+					n++
+				}
+			}
+			return n
+		}
+
+		best := 0
+		for i, c := range remaining[1:] {
+			b := remaining[best]
+			cBase, bBase := len(c.baseConflicts) > 0, len(b.baseConflicts) > 0
+			switch {
+			case cBase != bBase:
+				if !cBase {
+					best = i + 1
+				}
+			case openConflicts(c) < openConflicts(b):
+				best = i + 1
+			}
+		}
+
+		order = append(order, remaining[best])
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+	return order
+}
+
+// plural returns "s" unless n is 1
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
@@ -3,11 +3,12 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
-	"runtime"
+	"path/filepath"
 
 	"github.com/satoruhiga/wtree/internal/config"
 	"github.com/satoruhiga/wtree/internal/git"
+	"github.com/satoruhiga/wtree/internal/session"
+	"github.com/satoruhiga/wtree/internal/shell"
 	"github.com/spf13/cobra"
 )
 
@@ -17,7 +18,8 @@ var execCmd = &cobra.Command{
 	Long: `Execute the command specified in terminal.exec config.
 
 This is useful for starting your development environment (e.g., claude, vim)
-in the current worktree.
+in the current worktree. In a worktree created with 'wtree new --profile',
+the profile's terminal.exec is used.
 
 Examples:
   wtree exec  # Run terminal.exec command`,
@@ -42,17 +44,21 @@ func runExec(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Load sessions
+	store := session.NewStore(repoRoot)
+	if err := store.Load(); err != nil {
+		return err
+	}
+	if sess, ok := currentSession(store); ok {
+		cfg = sessionConfig(cfg, sess)
+	}
+
 	if cfg.Terminal.Exec == "" {
 		return fmt.Errorf("terminal.exec not configured in .wtree/config.toml")
 	}
 
 	// Execute command
-	var execCommand *exec.Cmd
-	if runtime.GOOS == "windows" {
-		execCommand = exec.Command("cmd", "/c", cfg.Terminal.Exec)
-	} else {
-		execCommand = exec.Command("sh", "-c", cfg.Terminal.Exec)
-	}
+	execCommand := shell.Command(cfg.Terminal.Exec)
 
 	execCommand.Stdin = os.Stdin
 	execCommand.Stdout = os.Stdout
@@ -60,3 +66,27 @@ func runExec(cmd *cobra.Command, args []string) error {
 
 	return execCommand.Run()
 }
+
+// currentSession returns the session of the worktree containing the current
+// directory
+func currentSession(store *session.Store) (*session.Session, bool) {
+	root, err := git.GetCurrentWorktreeRoot()
+	if err != nil {
+		return nil, false
+	}
+	root = resolvePath(root)
+	for _, sess := range store.All() {
+		if resolvePath(sess.AbsPath) == root {
+			return sess, true
+		}
+	}
+	return nil, false
+}
+
+// resolvePath resolves symlinks in path, or returns it cleaned if it can't be resolved
+func resolvePath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/satoruhiga/wtree/internal/config"
+	"github.com/satoruhiga/wtree/internal/git"
+	"github.com/satoruhiga/wtree/internal/session"
+	"github.com/satoruhiga/wtree/internal/shell"
+	"github.com/satoruhiga/wtree/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var foreachCmd = &cobra.Command{
+	Use:   "foreach [flags] -- <command> [args...]",
+	Short: "Run a command in every worktree",
+	Long: `Run a command in every managed worktree, optionally filtered by status.
+
+A single argument is run through the shell; multiple arguments are executed
+directly. WTREE_ID, WTREE_NAME, WTREE_BRANCH and WTREE_PATH are set for the command.
+Stale worktrees are skipped. --status ahead and --status behind select by
+commit count, so they include diverged worktrees and ones with uncommitted
+changes.
+
+Output modes (--output):
+  prefix     - Each line is prefixed with the worktree ID (default)
+  interleave - Lines are printed as they arrive, without prefix
+  group      - Output of each worktree is printed as a block when it finishes
+
+Exits with a non-zero status if the command failed in any worktree.
+
+Examples:
+  wtree foreach -- go test ./...
+  wtree foreach --status ahead -- git log --oneline -1
+  wtree foreach --parallel 1 --output group -- 'git pull --rebase && make'`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runForeach,
+}
+
+var (
+	foreachStatus   string
+	foreachParallel int
+	foreachOutput   string
+)
+
+func init() {
+	foreachCmd.Flags().StringVar(&foreachStatus, "status", "", "Only run in worktrees with this status (clean, uncommitted, ahead, behind, diverged, merged)")
+	foreachCmd.Flags().IntVarP(&foreachParallel, "parallel", "p", runtime.NumCPU(), "Number of worktrees to run concurrently")
+	foreachCmd.Flags().StringVarP(&foreachOutput, "output", "o", "prefix", "Output mode: prefix, interleave or group")
+	rootCmd.AddCommand(foreachCmd)
+}
+
+// foreachResult is the outcome of running the command in one worktree
+type foreachResult struct {
+	sess     *session.Session
+	exitCode int
+	duration time.Duration
+	err      error
+}
+
+func runForeach(cmd *cobra.Command, args []string) error {
+	switch foreachOutput {
+	case "prefix", "interleave", "group":
+	default:
+		return fmt.Errorf("unknown output mode '%s' (use prefix, interleave or group)", foreachOutput)
+	}
+	if foreachParallel < 1 {
+		foreachParallel = 1
+	}
+
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	// Load configuration
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	// Load sessions
+	store := session.NewStore(repoRoot)
+	if err := store.Load(); err != nil {
+		return err
+	}
+
+	gray := color.New(color.FgHiBlack).SprintFunc()
+
+	// Select target worktrees
+	sessions := store.All()
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+
+	var targets []*session.Session
+	if foreachStatus == "" {
+		for _, sess := range sessions {
+			if !git.WorktreeExists(sess.AbsPath) {
+				fmt.Println(gray("Skipping stale worktree " + sess.ID))
+				continue
+			}
+			targets = append(targets, sess)
+		}
+	} else {
+		entries, err := buildLsEntries(cfg, sessions)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if !entry.Stale && matchesStatus(entry, foreachStatus) {
+				targets = append(targets, entry.Session)
+			}
+		}
+	}
+
+	if len(targets) == 0 {
+		fmt.Println("No worktrees found.")
+		return nil
+	}
+
+	// Run with a bounded worker pool
+	results := make([]foreachResult, len(targets))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, foreachParallel)
+
+	for i, sess := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, sess *session.Session) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = runInWorktree(sess, args, i, &mu)
+		}(i, sess)
+	}
+	wg.Wait()
+
+	// Print summary
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	fmt.Println()
+	headers := []string{"ID", "NAME", "BRANCH", "EXIT", "DURATION"}
+	var rows [][]string
+	failed := 0
+	for _, result := range results {
+		exitStr := green("0")
+		if result.err != nil {
+			failed++
+			if result.exitCode >= 0 {
+				exitStr = red(strconv.Itoa(result.exitCode))
+			} else {
+				exitStr = red(result.err.Error())
+			}
+		}
+		rows = append(rows, []string{
+			result.sess.ID,
+			result.sess.Name,
+			result.sess.Branch,
+			exitStr,
+			result.duration.Round(time.Millisecond).String(),
+		})
+	}
+	ui.PrintTable(headers, rows)
+
+	if failed > 0 {
+		exitWithError("command failed in %d of %d worktree(s)", failed, len(results))
+	}
+	return nil
+}
+
+// foreachColors are cycled through for worktree prefixes
+var foreachColors = []color.Attribute{
+	color.FgCyan, color.FgYellow, color.FgGreen, color.FgMagenta, color.FgBlue,
+}
+
+// runInWorktree runs the command in a single worktree
+func runInWorktree(sess *session.Session, args []string, index int, mu *sync.Mutex) foreachResult {
+	var execCmd *exec.Cmd
+	if len(args) == 1 {
+		execCmd = shell.Command(args[0])
+	} else {
+		execCmd = exec.Command(args[0], args[1:]...)
+	}
+	execCmd.Dir = sess.AbsPath
+	execCmd.Env = append(os.Environ(),
+		"WTREE_ID="+sess.ID,
+		"WTREE_NAME="+sess.Name,
+		"WTREE_BRANCH="+sess.Branch,
+		"WTREE_PATH="+sess.AbsPath,
+	)
+
+	label := color.New(foreachColors[index%len(foreachColors)]).Sprint(sess.ID)
+
+	var out io.Writer
+	var group bytes.Buffer
+	var prefixWriter *ui.PrefixWriter
+	switch foreachOutput {
+	case "group":
+		out = &group
+	case "interleave":
+		prefixWriter = ui.NewPrefixWriter(os.Stdout, "", mu)
+		out = prefixWriter
+	default:
+		prefixWriter = ui.NewPrefixWriter(os.Stdout, label+" | ", mu)
+		out = prefixWriter
+	}
+	execCmd.Stdout = out
+	execCmd.Stderr = out
+
+	start := time.Now()
+	err := execCmd.Run()
+	result := foreachResult{sess: sess, duration: time.Since(start), err: err}
+	if err != nil {
+		result.exitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.exitCode = exitErr.ExitCode()
+		}
+	}
+
+	if prefixWriter != nil {
+		prefixWriter.Flush()
+	}
+	if foreachOutput == "group" {
+		mu.Lock()
+		fmt.Printf("==> %s (%s) <==\n", label, sess.Branch)
+		os.Stdout.Write(group.Bytes())
+		mu.Unlock()
+	}
+
+	return result
+}
+
+// matchesStatus reports whether a worktree has the status given to --status.
+// "ahead" and "behind" go by the commit counts, as a worktree that is both
+// has the status "diverged".
+func matchesStatus(entry lsEntry, status string) bool {
+	if entry.Status == git.StatusMerged.String() {
+		return status == entry.Status
+	}
+	switch status {
+	case git.StatusAhead.String():
+		return entry.AheadCount > 0
+	case git.StatusBehind.String():
+		return entry.BehindCount > 0
+	default:
+		return entry.Status == status
+	}
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/satoruhiga/wtree/internal/config"
+	"github.com/satoruhiga/wtree/internal/hooks"
+	"github.com/satoruhiga/wtree/internal/session"
+)
+
+// sessionHookContext builds the hook context for an existing session
+func sessionHookContext(repoRoot string, cfg *config.Config, sess *session.Session) hooks.Context {
+	cfg = profileConfig(cfg, sess)
+	return hooks.Context{
+		RepoRoot:   repoRoot,
+		ID:         sess.ID,
+		Name:       sess.Name,
+		Branch:     sess.Branch,
+		Path:       sess.AbsPath,
+		BaseBranch: cfg.Worktree.BaseBranch,
+		Profile:    sess.Profile,
+	}
+}
+
+// sessionConfig returns the configuration with the profile a session was
+// created with applied. If the profile no longer exists, it warns and returns
+// cfg unchanged.
+func sessionConfig(cfg *config.Config, sess *session.Session) *config.Config {
+	profileCfg, err := cfg.WithProfile(sess.Profile)
+	if err != nil {
+		yellow := color.New(color.FgYellow).SprintFunc()
+		fmt.Fprintf(os.Stderr, "%s %v, using the default settings\n", yellow("Warning:"), err)
+		return cfg
+	}
+	return profileCfg
+}
+
+// profileConfig is sessionConfig without the warning, for commands that
+// list sessions rather than act on one
+func profileConfig(cfg *config.Config, sess *session.Session) *config.Config {
+	// A profile removed from the config since falls back to the base settings
+	if profileCfg, err := cfg.WithProfile(sess.Profile); err == nil {
+		return profileCfg
+	}
+	return cfg
+}
+
+// runPostHook runs a post-* hook; failures are reported but do not abort
+func runPostHook(cfg *config.Config, event hooks.Event, ctx hooks.Context) {
+	if err := hooks.Run(cfg.Hooks, event, ctx); err != nil {
+		yellow := color.New(color.FgYellow).SprintFunc()
+		fmt.Printf("%s %v\n", yellow("Warning:"), err)
+	}
+}
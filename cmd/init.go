@@ -57,10 +57,15 @@ func runInit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
+	// Sessions, the trash and config.local.toml stay out of git
+	if err := config.WriteIgnoreFile(repoRoot); err != nil {
+		return err
+	}
+
 	green := color.New(color.FgGreen).SprintFunc()
 	fmt.Printf("%s Created .wtree/config.toml\n", green("✓"))
 	fmt.Printf("Base branch: %s\n", baseBranch)
-	fmt.Println("\nTip: Add .wtree/ to your .gitignore")
+	fmt.Println("\nTip: Commit .wtree/config.toml to share these settings; the rest of .wtree is ignored")
 
 	return nil
 }
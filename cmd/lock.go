@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/satoruhiga/wtree/internal/git"
+	"github.com/satoruhiga/wtree/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var lockCmd = &cobra.Command{
+	Use:   "lock <id>",
+	Short: "Protect a worktree from rm, merge and prune",
+	Long: `Lock a worktree so that 'wtree rm', 'wtree merge' and 'wtree prune' refuse
+to remove it. The worktree is also locked with 'git worktree lock', so git
+itself won't prune or remove it.
+
+Pass --ignore-lock to rm, merge or prune to override the lock once.
+
+Examples:
+  wtree lock a3f8
+  wtree lock a3f8 --reason "long-running benchmark"
+  wtree unlock a3f8`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLock,
+}
+
+var unlockCmd = &cobra.Command{
+	Use:   "unlock <id>",
+	Short: "Remove the lock from a worktree",
+	Long: `Remove a lock set with 'wtree lock' or 'git worktree lock'.
+
+Examples:
+  wtree unlock a3f8`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUnlock,
+}
+
+var lockReason string
+
+func init() {
+	lockCmd.Flags().StringVar(&lockReason, "reason", "", "Why the worktree is locked")
+	rootCmd.AddCommand(lockCmd)
+	rootCmd.AddCommand(unlockCmd)
+}
+
+func runLock(cmd *cobra.Command, args []string) error {
+	partialID := args[0]
+
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	// Load sessions
+	store := session.NewStore(repoRoot)
+	if err := store.Load(); err != nil {
+		return err
+	}
+
+	// Find session by partial ID
+	sess, err := store.FindByPartialID(partialID)
+	if err != nil {
+		return err
+	}
+	if !git.WorktreeExists(sess.AbsPath) {
+		return fmt.Errorf("worktree %s no longer exists", sess.ID)
+	}
+
+	// git refuses to lock twice; an existing git lock keeps its reason
+	if locked, _ := git.WorktreeLock(sess.AbsPath); !locked {
+		if err := git.LockWorktree(sess.AbsPath, lockReason); err != nil {
+			return err
+		}
+	}
+
+	if err := store.Update(func(s *session.Store) error {
+		sess, err := s.FindByPartialID(sess.ID)
+		if err != nil {
+			return err
+		}
+		sess.Lock = &session.LockState{Reason: lockReason, LockedAt: time.Now()}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to update sessions: %w", err)
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	if lockReason != "" {
+		fmt.Printf("%s Locked %s (%s)\n", green("✓"), sess.ID, lockReason)
+	} else {
+		fmt.Printf("%s Locked %s\n", green("✓"), sess.ID)
+	}
+	return nil
+}
+
+func runUnlock(cmd *cobra.Command, args []string) error {
+	partialID := args[0]
+
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	// Load sessions
+	store := session.NewStore(repoRoot)
+	if err := store.Load(); err != nil {
+		return err
+	}
+
+	// Find session by partial ID
+	sess, err := store.FindByPartialID(partialID)
+	if err != nil {
+		return err
+	}
+
+	if locked, _ := worktreeLocked(sess); !locked {
+		fmt.Printf("%s is not locked\n", sess.ID)
+		return nil
+	}
+
+	if err := releaseLock(sess); err != nil {
+		return err
+	}
+	if err := store.Update(func(s *session.Store) error {
+		sess, err := s.FindByPartialID(sess.ID)
+		if err != nil {
+			return err
+		}
+		sess.Lock = nil
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to update sessions: %w", err)
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	fmt.Printf("%s Unlocked %s\n", green("✓"), sess.ID)
+	return nil
+}
+
+// worktreeLocked reports whether a session's worktree is locked, by
+// 'wtree lock' or directly with 'git worktree lock', and why
+func worktreeLocked(sess *session.Session) (bool, string) {
+	if sess.Lock != nil {
+		return true, sess.Lock.Reason
+	}
+	return git.WorktreeLock(sess.AbsPath)
+}
+
+// checkNotLocked returns an error for a locked worktree unless ignoreLock is set
+func checkNotLocked(sess *session.Session, ignoreLock bool) error {
+	locked, reason := worktreeLocked(sess)
+	if !locked || ignoreLock {
+		return nil
+	}
+	if reason != "" {
+		return fmt.Errorf("worktree %s is locked (%s). Run 'wtree unlock %s' or pass --ignore-lock", sess.ID, reason, sess.ID)
+	}
+	return fmt.Errorf("worktree %s is locked. Run 'wtree unlock %s' or pass --ignore-lock", sess.ID, sess.ID)
+}
+
+// releaseLock removes the git lock from a worktree, which 'git worktree
+// remove' would otherwise refuse. The session's lock is left to the caller.
+func releaseLock(sess *session.Session) error {
+	if locked, _ := git.WorktreeLock(sess.AbsPath); !locked {
+		return nil
+	}
+	return git.UnlockWorktree(sess.AbsPath)
+}
@@ -20,16 +20,66 @@ Status types:
   clean       - No changes
   uncommitted - Has uncommitted changes
   ahead N     - N commits ahead of base branch
-  merged      - Already merged to base branch
-  stale       - Worktree no longer exists (use 'wtree rm' to clean up)`,
+  behind N    - N commits behind base branch (both when diverged)
+  merged      - Already merged to base branch; "merged (squash)" or
+                "merged (rebase)" when the changes landed without the branch
+  stale       - Worktree no longer exists (use 'wtree rm' to clean up)
+
+A rebase, merge, cherry-pick, revert or bisect stopped in a worktree is
+shown first ("rebasing, uncommitted"). Details follow in parentheses:
+counts of conflicted, staged, modified and untracked files, and the
+"branch missing", "detached" and "locked" (wtree lock) flags. For example:
+  uncommitted, ahead 2, behind 30 (1 staged, 2 modified, 3 untracked, locked)
+
+A "verified" or "failed" suffix shows the result of 'wtree verify' (or the
+merge.verify gate) if the branch has not changed since.
+
+Output formats (--format):
+  table       - Colored table (default)
+  json        - JSON array
+  ndjson      - One JSON object per line
+  yaml        - YAML list
+  '{{.ID}} {{.Branch}}' - Go template, executed once per worktree
+
+Examples:
+  wtree ls
+  wtree ls --format json
+  wtree ls --format '{{.ID}} {{.Status}} {{.AbsPath}}'`,
 	RunE: runLs,
 }
 
+var lsFormat string
+
 func init() {
+	lsCmd.Flags().StringVar(&lsFormat, "format", ui.FormatTable, "Output format: table, json, ndjson, yaml or a Go template")
 	rootCmd.AddCommand(lsCmd)
 }
 
+// lsEntry is a session with its computed status, as emitted by structured formats
+type lsEntry struct {
+	*session.Session
+	Status        string `json:"status"`
+	AheadCount    int    `json:"ahead_count"`
+	BehindCount   int    `json:"behind_count"`
+	Staged        int    `json:"staged"`
+	Unstaged      int    `json:"unstaged"`
+	Untracked     int    `json:"untracked"`
+	Conflicted    int    `json:"conflicted"`
+	MergedBy      string `json:"merged_by,omitempty"`
+	Operation     string `json:"operation,omitempty"`
+	Locked        bool   `json:"locked"`
+	Detached      bool   `json:"detached"`
+	BranchMissing bool   `json:"branch_missing"`
+	Stale         bool   `json:"stale"`
+	Description   string `json:"description"`
+	Verify        string `json:"verify,omitempty"`
+}
+
 func runLs(cmd *cobra.Command, args []string) error {
+	if err := ui.ValidateFormat(lsFormat); err != nil {
+		return err
+	}
+
 	// Get repository root
 	repoRoot, err := git.GetRepoRoot()
 	if err != nil {
@@ -49,8 +99,8 @@ func runLs(cmd *cobra.Command, args []string) error {
 	}
 
 	sessions := store.All()
-	if len(sessions) == 0 {
-		if !config.Exists(repoRoot) {
+	if len(sessions) == 0 && lsFormat == ui.FormatTable {
+		if len(cfg.Sources()) == 0 {
 			cmd.Println("No .wtree found. Run 'wtree init' to initialize.")
 		} else {
 			cmd.Println("No worktrees found.")
@@ -63,8 +113,18 @@ func runLs(cmd *cobra.Command, args []string) error {
 		return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
 	})
 
+	// Compute status for each session
+	entries, err := buildLsEntries(cfg, sessions)
+	if err != nil {
+		return err
+	}
+
+	if lsFormat != ui.FormatTable {
+		return ui.PrintFormatted(lsFormat, entries)
+	}
+
 	// Build table data
-	headers := []string{"ID", "BRANCH", "CREATED", "STATUS", "PATH"}
+	headers := []string{"ID", "NAME", "BRANCH", "CREATED", "STATUS", "PATH"}
 	var rows [][]string
 
 	yellow := color.New(color.FgYellow).SprintFunc()
@@ -73,42 +133,137 @@ func runLs(cmd *cobra.Command, args []string) error {
 	red := color.New(color.FgRed).SprintFunc()
 	gray := color.New(color.FgHiBlack).SprintFunc()
 
-	for _, sess := range sessions {
+	for _, entry := range entries {
 		var statusStr string
+		switch entry.Status {
+		case git.StatusClean.String():
+			statusStr = green(entry.Description)
+		case git.StatusUncommitted.String():
+			statusStr = red(entry.Description)
+		case git.StatusAhead.String(), git.StatusBehind.String(), git.StatusDiverged.String():
+			statusStr = yellow(entry.Description)
+		case git.StatusMerged.String():
+			statusStr = cyan(entry.Description)
+		default:
+			statusStr = gray(entry.Description)
+		}
 
-		// First check if worktree exists
-		if !git.WorktreeExists(sess.AbsPath) {
-			statusStr = gray("stale")
-		} else {
-			// Get status
-			statusInfo, err := git.GetStatus(sess.AbsPath, cfg.Worktree.BaseBranch, sess.Branch)
-			if err != nil {
-				statusStr = gray("unknown")
-			} else {
-				switch statusInfo.Status {
-				case git.StatusClean:
-					statusStr = green(statusInfo.Description)
-				case git.StatusUncommitted:
-					statusStr = red(statusInfo.Description)
-				case git.StatusAhead:
-					statusStr = yellow(statusInfo.Description)
-				case git.StatusMerged:
-					statusStr = cyan(statusInfo.Description)
-				default:
-					statusStr = gray("unknown")
-				}
-			}
+		switch entry.Verify {
+		case "verified":
+			statusStr += " " + green("(verified)")
+		case "failed":
+			statusStr += " " + red("(failed)")
 		}
 
 		rows = append(rows, []string{
-			sess.ID,
-			sess.Branch,
-			sess.RelativeTime(),
+			entry.ID,
+			entry.Name,
+			entry.Branch,
+			entry.RelativeTime(),
 			statusStr,
-			sess.Path,
+			entry.Path,
 		})
 	}
 
 	ui.PrintTable(headers, rows)
 	return nil
 }
+
+// buildLsEntries computes the status of each session, sharing one snapshot
+// of the repository per base branch between them
+func buildLsEntries(cfg *config.Config, sessions []*session.Session) ([]lsEntry, error) {
+	statuses, err := sessionStatuses(cfg, sessions, git.NewSnapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]lsEntry, len(statuses))
+	for i, st := range statuses {
+		entries[i] = buildLsEntry(st.snap, st.sess, st.result)
+	}
+	return entries, nil
+}
+
+// sessionStatus is a session with its own configuration and status
+type sessionStatus struct {
+	sess   *session.Session
+	cfg    *config.Config // With the session's profile applied
+	snap   *git.Snapshot  // Taken against cfg.Worktree.BaseBranch
+	result git.StatusResult
+}
+
+// sessionStatuses computes the status of each session against the base
+// branch of its profile. newSnapshot is called once per base branch.
+func sessionStatuses(cfg *config.Config, sessions []*session.Session, newSnapshot func(baseBranch string) (*git.Snapshot, error)) ([]sessionStatus, error) {
+	statuses := make([]sessionStatus, len(sessions))
+	var bases []string
+	byBase := make(map[string][]int)
+	for i, sess := range sessions {
+		sessCfg := profileConfig(cfg, sess)
+		statuses[i] = sessionStatus{sess: sess, cfg: sessCfg}
+		base := sessCfg.Worktree.BaseBranch
+		if _, ok := byBase[base]; !ok {
+			bases = append(bases, base)
+		}
+		byBase[base] = append(byBase[base], i)
+	}
+
+	for _, base := range bases {
+		indexes := byBase[base]
+		snap, err := newSnapshot(base)
+		if err != nil {
+			return nil, err
+		}
+		targets := make([]git.StatusTarget, len(indexes))
+		for j, i := range indexes {
+			targets[j] = statusTarget(sessions[i])
+		}
+		for j, result := range snap.Statuses(targets) {
+			statuses[indexes[j]].snap = snap
+			statuses[indexes[j]].result = result
+		}
+	}
+	return statuses, nil
+}
+
+// statusTarget returns what the git package needs to compute a session's status
+func statusTarget(sess *session.Session) git.StatusTarget {
+	return git.StatusTarget{Path: sess.AbsPath, Branch: sess.Branch, Locked: sess.Lock != nil}
+}
+
+// buildLsEntry turns a computed status into an lsEntry
+func buildLsEntry(snap *git.Snapshot, sess *session.Session, result git.StatusResult) lsEntry {
+	entry := lsEntry{Session: sess}
+
+	// Verification results only apply to the commit they were run on
+	if v := sess.Verification; v != nil && snap.Head(sess.Branch) == v.Commit {
+		if v.Passed {
+			entry.Verify = "verified"
+		} else {
+			entry.Verify = "failed"
+		}
+	}
+
+	if result.Err != nil {
+		entry.Status = "unknown"
+		entry.Description = entry.Status
+		return entry
+	}
+
+	info := result.Info
+	entry.Status = info.Status.String()
+	entry.Stale = info.Status == git.StatusStale
+	entry.AheadCount = info.AheadCount
+	entry.BehindCount = info.BehindCount
+	entry.Staged = info.Staged
+	entry.Unstaged = info.Unstaged
+	entry.Untracked = info.Untracked
+	entry.Conflicted = info.Conflicted
+	entry.MergedBy = info.MergedBy
+	entry.Operation = info.Operation
+	entry.Locked = info.Locked
+	entry.Detached = info.Detached
+	entry.BranchMissing = info.BranchMissing
+	entry.Description = info.Description
+	return entry
+}
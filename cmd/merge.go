@@ -1,32 +1,87 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/satoruhiga/wtree/internal/config"
 	"github.com/satoruhiga/wtree/internal/git"
+	"github.com/satoruhiga/wtree/internal/hooks"
 	"github.com/satoruhiga/wtree/internal/session"
+	"github.com/satoruhiga/wtree/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var mergeCmd = &cobra.Command{
 	Use:   "merge <id>",
 	Short: "Merge a worktree branch and remove the worktree",
-	Long: `Merge the worktree's branch into the current branch and remove the worktree.
+	Long: `Merge the worktree's branch into the base branch (worktree.base_branch,
+or --into) and remove the worktree. The branch you are currently on does not
+matter: if the target is checked out somewhere, the merge happens there;
+otherwise it is fast-forwarded directly or merged in a temporary worktree.
 If there are conflicts, the worktree is kept for manual resolution.
 
+If merge.verify lists commands, they must all pass in the worktree before the
+merge happens (see 'wtree verify').
+
+Strategies (--strategy, or merge.strategy in config):
+  merge    - git merge, fast-forwarding when possible (default)
+  squash   - Squash all commits into one; the message lists the commit subjects
+  rebase   - Rebase the branch onto the target, then fast-forward
+  ff-only  - Fast-forward only; fails if the branch has diverged
+  no-ff    - Always create a merge commit
+
 Examples:
-  wtree merge a3f8      # Merge and remove worktree`,
-	Args: cobra.ExactArgs(1),
+  wtree merge a3f8                       # Merge and remove worktree
+  wtree merge a3f8 --strategy squash -e  # Squash, editing the message in $EDITOR
+  wtree merge a3f8 -s squash -m "Add login form"
+  wtree merge a3f8 --into release-1.2    # Merge into another branch
+
+Resolving conflicts:
+  wtree merge --status     # Show the conflicted files
+  wtree merge --continue   # Commit the resolution and remove the worktree
+  wtree merge --abort      # Undo the merge and keep the worktree`,
+	Args: cobra.RangeArgs(0, 1),
 	RunE: runMerge,
 }
 
+var (
+	mergeStrategy   string
+	mergeMessage    string
+	mergeEdit       bool
+	mergeInto       string
+	mergeContinue   bool
+	mergeAbort      bool
+	mergeStatus     bool
+	mergeNoVerify   bool
+	mergeIgnoreLock bool
+)
+
 func init() {
+	mergeCmd.Flags().StringVarP(&mergeStrategy, "strategy", "s", "", "Merge strategy: merge, squash, rebase, ff-only or no-ff")
+	mergeCmd.Flags().StringVarP(&mergeMessage, "message", "m", "", "Commit message for squash and no-ff merges")
+	mergeCmd.Flags().BoolVarP(&mergeEdit, "edit", "e", false, "Edit the commit message in $EDITOR")
+	mergeCmd.Flags().StringVar(&mergeInto, "into", "", "Branch to merge into (default: worktree.base_branch)")
+	mergeCmd.Flags().BoolVar(&mergeContinue, "continue", false, "Finish a merge after resolving conflicts")
+	mergeCmd.Flags().BoolVar(&mergeAbort, "abort", false, "Abort a conflicted merge")
+	mergeCmd.Flags().BoolVar(&mergeStatus, "status", false, "Show the state of a conflicted merge")
+	mergeCmd.Flags().BoolVar(&mergeNoVerify, "no-verify", false, "Skip the merge.verify commands")
+	mergeCmd.Flags().BoolVar(&mergeIgnoreLock, "ignore-lock", false, "Merge and remove the worktree even if it is locked")
+	mergeCmd.MarkFlagsMutuallyExclusive("continue", "abort", "status")
 	rootCmd.AddCommand(mergeCmd)
 }
 
 func runMerge(cmd *cobra.Command, args []string) error {
+	if mergeContinue || mergeAbort || mergeStatus {
+		return runMergeResume(args)
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("requires a worktree ID")
+	}
 	partialID := args[0]
 
 	// Get repository root
@@ -52,6 +107,35 @@ func runMerge(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if sess.PendingMerge != nil {
+		return fmt.Errorf("a merge of %s is already in progress. Use --continue, --abort or --status", sess.ID)
+	}
+	if err := checkNotLocked(sess, mergeIgnoreLock); err != nil {
+		return err
+	}
+	cfg = sessionConfig(cfg, sess)
+
+	// Determine strategy
+	strategyName := cfg.Merge.Strategy
+	if mergeStrategy != "" {
+		strategyName = mergeStrategy
+	}
+	strategy, err := git.ParseMergeStrategy(strategyName)
+	if err != nil {
+		return err
+	}
+
+	// Determine target branch
+	target := cfg.Worktree.BaseBranch
+	if mergeInto != "" {
+		target = mergeInto
+	}
+	if !git.LocalBranchExists(target) {
+		return fmt.Errorf("target branch not found: %s", target)
+	}
+	if target == sess.Branch {
+		return fmt.Errorf("cannot merge %s into itself", target)
+	}
 
 	// Check if we're in the worktree itself
 	currentBranch, _ := git.GetCurrentBranch()
@@ -69,25 +153,111 @@ func runMerge(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get ahead count for display
-	aheadCount, _ := git.GetAheadCount(cfg.Worktree.BaseBranch, sess.Branch)
+	aheadCount, _ := git.GetAheadCount(target, sess.Branch)
+
+	// Run verification gate
+	if len(cfg.Merge.Verify) > 0 && !mergeNoVerify {
+		result, err := verifySession(repoRoot, cfg, store, sess, target)
+		if err != nil {
+			return err
+		}
+		if !result.Passed {
+			return fmt.Errorf("verification failed, not merging %s (use --no-verify to skip)", sess.ID)
+		}
+	}
+
+	hookCtx := sessionHookContext(repoRoot, cfg, sess)
+	hookCtx.BaseBranch = target
+	if err := hooks.Run(cfg.Hooks, hooks.PreMerge, hookCtx); err != nil {
+		return err
+	}
+
+	// Prepare the commit message up front so it survives a conflict
+	message, err := mergeCommitMessage(sess, target, strategy)
+	if err != nil {
+		return err
+	}
 
 	// Merge
-	fmt.Printf("Merging %s into %s...\n", sess.Branch, currentBranch)
-	if err := git.Merge(sess.Branch); err != nil {
-		if err.Error() == "merge conflict detected" {
-			yellow := color.New(color.FgYellow).SprintFunc()
-			fmt.Printf("%s Conflict detected. Resolve manually.\n", yellow("!"))
+	fmt.Printf("Merging %s into %s (%s)...\n", sess.Branch, target, strategy)
+	dir, temporary, err := mergeBranch(repoRoot, cfg, sess, target, strategy, message)
+	if err != nil {
+		if !errors.Is(err, git.ErrMergeConflict) {
+			return err
+		}
+
+		yellow := color.New(color.FgYellow).SprintFunc()
+		if strategy == git.StrategyRebase {
+			fmt.Printf("%s Conflict detected while rebasing. The rebase was aborted.\n", yellow("!"))
 			fmt.Printf("Worktree kept at: %s\n", sess.Path)
 			return nil
 		}
-		return err
+
+		// Record the merge so it can be continued or aborted
+		targetHead, _ := git.RevParse(target)
+		state := &session.MergeState{
+			Target:     target,
+			TargetHead: targetHead,
+			Strategy:   string(strategy),
+			Dir:        dir,
+			Temporary:  temporary,
+			Message:    message,
+			StartedAt:  time.Now(),
+		}
+		if err := store.Update(func(s *session.Store) error {
+			if current, ok := s.Get(sess.ID); ok {
+				current.PendingMerge = state
+			}
+			return nil
+		}); err != nil {
+			return fmt.Errorf("failed to update sessions: %w", err)
+		}
+
+		fmt.Printf("%s Conflict detected in: %s\n", yellow("!"), dir)
+		printConflictedFiles(dir)
+		fmt.Println("Resolve the conflicts, 'git add' the files, then run 'wtree merge --continue'.")
+		fmt.Println("Run 'wtree merge --abort' to give up.")
+		return nil
 	}
 
+	return finishMerge(repoRoot, cfg, store, sess, target, strategy, aheadCount)
+}
+
+// finishMerge reports a successful merge, then removes the worktree, its
+// branch and its session
+func finishMerge(repoRoot string, cfg *config.Config, store *session.Store, sess *session.Session, target string, strategy git.MergeStrategy, aheadCount int) error {
+	hookCtx := sessionHookContext(repoRoot, cfg, sess)
+	hookCtx.BaseBranch = target
+
 	green := color.New(color.FgGreen).SprintFunc()
+	verb := "Merged"
+	if strategy == git.StrategySquash {
+		verb = "Squashed"
+	}
 	if aheadCount > 0 {
-		fmt.Printf("%s Merged %d commits\n", green("✓"), aheadCount)
+		fmt.Printf("%s %s %d commit%s\n", green("✓"), verb, aheadCount, plural(aheadCount))
 	} else {
-		fmt.Printf("%s Merged\n", green("✓"))
+		fmt.Printf("%s %s\n", green("✓"), verb)
+	}
+
+	runPostHook(cfg, hooks.PostMerge, hookCtx)
+
+	if err := hooks.Run(cfg.Hooks, hooks.PreRemove, hookCtx); err != nil {
+		fmt.Printf("Worktree kept at: %s\n", sess.Path)
+		return err
+	}
+
+	// Keep the branch restorable; squashed commits are deleted with force below
+	trashed, err := trashSession(repoRoot, cfg, sess, git.WorktreeExists(sess.AbsPath))
+	if err != nil {
+		fmt.Printf("Worktree kept at: %s\n", sess.Path)
+		return err
+	}
+
+	closeTerminals(cfg, sess.AbsPath)
+
+	if err := releaseLock(sess); err != nil {
+		fmt.Printf("Warning: %v\n", err)
 	}
 
 	// Remove worktree
@@ -95,18 +265,161 @@ func runMerge(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Warning: failed to remove worktree: %v\n", err)
 	}
 
-	// Delete branch
-	if err := git.DeleteBranch(sess.Branch, false); err != nil {
-		fmt.Printf("Warning: failed to delete branch: %v\n", err)
+	// Delete branch (unless wtree attached to an existing one).
+	// 'git branch -d' checks against HEAD rather than the target, and squashed
+	// commits are never reachable, so force once the merge is confirmed.
+	if sess.OwnsBranch() {
+		merged := strategy == git.StrategySquash || git.IsAncestor(sess.Branch, target)
+		if err := git.DeleteBranch(sess.Branch, merged); err != nil {
+			fmt.Printf("Warning: failed to delete branch: %v\n", err)
+		}
 	}
 
 	// Remove from sessions
-	store.Remove(sess.ID)
-	if err := store.Save(); err != nil {
+	if err := store.Update(func(s *session.Store) error {
+		s.Remove(sess.ID)
+		return nil
+	}); err != nil {
 		return fmt.Errorf("failed to update sessions: %w", err)
 	}
 
 	fmt.Printf("%s Removed worktree %s\n", green("✓"), sess.ID)
+	if trashed {
+		gray := color.New(color.FgHiBlack).SprintFunc()
+		fmt.Println(gray("  Restore with: wtree restore " + sess.ID))
+	}
+
+	runPostHook(cfg, hooks.PostRemove, hookCtx)
 
 	return nil
 }
+
+// mergeBranch integrates the session branch into target without touching
+// the caller's checkout. It returns the directory the merge ran in and whether
+// that is a temporary worktree; on ErrMergeConflict the directory is left
+// with the conflicted merge.
+func mergeBranch(repoRoot string, cfg *config.Config, sess *session.Session, target string, strategy git.MergeStrategy, message string) (string, bool, error) {
+	if strategy == git.StrategyRebase {
+		if err := git.Rebase(sess.AbsPath, target); err != nil {
+			return sess.AbsPath, false, err
+		}
+	}
+
+	// Merge where the target is checked out, so its working tree stays in sync
+	if dir, ok := git.FindBranchWorktree(target); ok {
+		return dir, false, mergeIn(dir, sess, strategy, message)
+	}
+
+	// Fast-forward the branch ref directly when no commit is needed
+	if strategy != git.StrategySquash && strategy != git.StrategyNoFF && git.IsAncestor(target, sess.Branch) {
+		return "", false, git.FastForwardBranch(target, sess.Branch)
+	}
+	if strategy == git.StrategyFFOnly || strategy == git.StrategyRebase {
+		return "", false, fmt.Errorf("cannot fast-forward: %s has diverged, rebase it first", sess.Branch)
+	}
+
+	// Otherwise merge in a temporary worktree
+	tempPath := filepath.Join(repoRoot, cfg.Worktree.WorktreeBaseDir, "merge-"+sess.ID)
+	if err := git.AddWorktreeForBranch(tempPath, target); err != nil {
+		return "", false, err
+	}
+	if err := mergeIn(tempPath, sess, strategy, message); err != nil {
+		if errors.Is(err, git.ErrMergeConflict) {
+			return tempPath, true, err
+		}
+		git.RemoveWorktree(tempPath, true)
+		return "", false, err
+	}
+	if err := git.RemoveWorktree(tempPath, true); err != nil {
+		fmt.Printf("Warning: failed to remove temporary worktree: %v\n", err)
+	}
+	return tempPath, true, nil
+}
+
+// mergeIn integrates the session branch into the branch checked out in dir
+func mergeIn(dir string, sess *session.Session, strategy git.MergeStrategy, message string) error {
+	switch strategy {
+	case git.StrategySquash:
+		if err := git.MergeSquash(dir, sess.Branch); err != nil {
+			return err
+		}
+		return git.Commit(dir, message)
+	case git.StrategyRebase, git.StrategyFFOnly:
+		return git.MergeFFOnly(dir, sess.Branch)
+	case git.StrategyNoFF:
+		return git.MergeNoFF(dir, sess.Branch, message)
+	default:
+		return git.Merge(dir, sess.Branch)
+	}
+}
+
+// mergeCommitMessage returns the commit message for strategies that create a
+// commit; empty means git's default
+func mergeCommitMessage(sess *session.Session, target string, strategy git.MergeStrategy) (string, error) {
+	switch strategy {
+	case git.StrategySquash:
+		return squashMessage(sess, target)
+	case git.StrategyNoFF:
+		if !mergeEdit {
+			return mergeMessage, nil
+		}
+		message := mergeMessage
+		if message == "" {
+			message = fmt.Sprintf("Merge branch '%s' into %s", sess.Branch, target)
+		}
+		edited, err := ui.EditText(message + "\n")
+		if err != nil {
+			return "", err
+		}
+		if edited == "" {
+			return "", fmt.Errorf("aborting merge due to empty commit message")
+		}
+		return edited, nil
+	default:
+		return "", nil
+	}
+}
+
+// squashMessage returns the commit message for a squash merge: -m if given,
+// otherwise one generated from the branch's commit subjects, optionally edited
+func squashMessage(sess *session.Session, target string) (string, error) {
+	message := mergeMessage
+	if message == "" {
+		subjects, err := git.CommitSubjects(target, sess.Branch)
+		if err != nil {
+			return "", err
+		}
+		message = buildSquashMessage(sess, subjects)
+	}
+
+	if mergeEdit {
+		edited, err := ui.EditText(message + "\n\n# Enter the commit message for the squashed changes.\n# Lines starting with '#' are ignored; an empty message aborts.\n")
+		if err != nil {
+			return "", err
+		}
+		message = edited
+	}
+
+	if strings.TrimSpace(message) == "" {
+		return "", fmt.Errorf("aborting merge due to empty commit message")
+	}
+	return message, nil
+}
+
+// buildSquashMessage summarizes squashed commits. A single commit keeps its
+// subject; several are listed in the body.
+func buildSquashMessage(sess *session.Session, subjects []string) string {
+	switch len(subjects) {
+	case 0:
+		return fmt.Sprintf("Squash %s", sess.DisplayName())
+	case 1:
+		return subjects[0]
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Squash %s (%d commits)\n\n", sess.DisplayName(), len(subjects))
+	for _, subject := range subjects {
+		fmt.Fprintf(&b, "* %s\n", subject)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
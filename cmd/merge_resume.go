@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/satoruhiga/wtree/internal/config"
+	"github.com/satoruhiga/wtree/internal/git"
+	"github.com/satoruhiga/wtree/internal/session"
+)
+
+// runMergeResume handles merge --continue, --abort and --status
+func runMergeResume(args []string) error {
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	// Load configuration
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	// Load sessions
+	store := session.NewStore(repoRoot)
+	if err := store.Load(); err != nil {
+		return err
+	}
+
+	sess, err := findPendingMerge(store, args)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case mergeStatus:
+		return showMergeStatus(sess)
+	case mergeAbort:
+		return abortPendingMerge(store, sess)
+	default:
+		if err := checkNotLocked(sess, mergeIgnoreLock); err != nil {
+			return err
+		}
+		return continuePendingMerge(repoRoot, sessionConfig(cfg, sess), store, sess)
+	}
+}
+
+// findPendingMerge returns the session with an unfinished merge, by ID if given
+func findPendingMerge(store *session.Store, args []string) (*session.Session, error) {
+	if len(args) == 1 {
+		sess, err := store.FindByPartialID(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if sess.PendingMerge == nil {
+			return nil, fmt.Errorf("no merge in progress for %s", sess.ID)
+		}
+		return sess, nil
+	}
+
+	var pending []*session.Session
+	for _, sess := range store.All() {
+		if sess.PendingMerge != nil {
+			pending = append(pending, sess)
+		}
+	}
+
+	switch len(pending) {
+	case 0:
+		return nil, fmt.Errorf("no merge in progress")
+	case 1:
+		return pending[0], nil
+	default:
+		return nil, fmt.Errorf("%d merges in progress. Please specify a worktree ID", len(pending))
+	}
+}
+
+// showMergeStatus prints the state of an unfinished merge
+func showMergeStatus(sess *session.Session) error {
+	state := sess.PendingMerge
+	fmt.Printf("Merging %s into %s (%s)\n", sess.Branch, state.Target, state.Strategy)
+	fmt.Printf("Directory: %s\n", state.Dir)
+
+	files, err := git.ConflictedFiles(state.Dir)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		green := color.New(color.FgGreen).SprintFunc()
+		fmt.Printf("%s All conflicts resolved. Run 'wtree merge --continue'.\n", green("✓"))
+		return nil
+	}
+	printConflictedFiles(state.Dir)
+	return nil
+}
+
+// printConflictedFiles lists the unmerged paths in dir
+func printConflictedFiles(dir string) {
+	files, err := git.ConflictedFiles(dir)
+	if err != nil || len(files) == 0 {
+		return
+	}
+
+	red := color.New(color.FgRed).SprintFunc()
+	fmt.Printf("Conflicted files (%d):\n", len(files))
+	for _, file := range files {
+		fmt.Printf("  %s\n", red(file))
+	}
+}
+
+// abortPendingMerge undoes an unfinished merge and keeps the worktree
+func abortPendingMerge(store *session.Store, sess *session.Session) error {
+	state := sess.PendingMerge
+	if err := undoPendingMerge(store, sess); err != nil {
+		return err
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	fmt.Printf("%s Aborted merge of %s into %s\n", green("✓"), sess.Branch, state.Target)
+	fmt.Printf("Worktree kept at: %s\n", sess.Path)
+	return nil
+}
+
+// undoPendingMerge aborts the merge in its directory, removes the temporary
+// worktree it ran in and forgets the merge state
+func undoPendingMerge(store *session.Store, sess *session.Session) error {
+	state := sess.PendingMerge
+
+	if _, err := os.Stat(state.Dir); err == nil {
+		if err := git.AbortMerge(state.Dir); err != nil {
+			return err
+		}
+	}
+	if state.Temporary {
+		if err := git.RemoveWorktree(state.Dir, true); err != nil {
+			fmt.Printf("Warning: failed to remove temporary worktree: %v\n", err)
+		}
+	}
+
+	if err := clearPendingMerge(store, sess.ID); err != nil {
+		return err
+	}
+	sess.PendingMerge = nil
+	return nil
+}
+
+// continuePendingMerge commits a resolved merge, then finishes the cleanup
+// that the conflicted merge skipped
+func continuePendingMerge(repoRoot string, cfg *config.Config, store *session.Store, sess *session.Session) error {
+	state := sess.PendingMerge
+	strategy, err := git.ParseMergeStrategy(state.Strategy)
+	if err != nil {
+		return err
+	}
+
+	files, err := git.ConflictedFiles(state.Dir)
+	if err != nil {
+		return err
+	}
+	if len(files) > 0 {
+		printConflictedFiles(state.Dir)
+		return fmt.Errorf("resolve the conflicts and 'git add' the files in %s first", state.Dir)
+	}
+
+	// Commit unless the user already did
+	if strategy == git.StrategySquash {
+		if git.HasStagedChanges(state.Dir) {
+			if err := git.Commit(state.Dir, state.Message); err != nil {
+				return err
+			}
+		}
+		if err := checkSquashLanded(store, sess); err != nil {
+			return err
+		}
+	} else {
+		if git.IsMergeInProgress(state.Dir) {
+			if err := git.ContinueMerge(state.Dir); err != nil {
+				return err
+			}
+		}
+		if !git.IsAncestor(sess.Branch, state.Target) {
+			return fmt.Errorf("%s is not merged into %s. Commit the merge in %s first", sess.Branch, state.Target, state.Dir)
+		}
+	}
+
+	if state.Temporary {
+		if err := git.RemoveWorktree(state.Dir, true); err != nil {
+			fmt.Printf("Warning: failed to remove temporary worktree: %v\n", err)
+		}
+	}
+
+	if err := clearPendingMerge(store, sess.ID); err != nil {
+		return err
+	}
+
+	return finishMerge(repoRoot, cfg, store, sess, state.Target, strategy, 0)
+}
+
+// checkSquashLanded verifies that a resumed squash merge committed the
+// branch's changes to the target. Nothing is committed if the merge was reset
+// or every conflict was resolved to the target's version, and the branch must
+// then be kept, as squashed commits are deleted with force.
+func checkSquashLanded(store *session.Store, sess *session.Session) error {
+	state := sess.PendingMerge
+
+	if state.TargetHead == "" {
+		return fmt.Errorf("the merge state of %s has no recorded head for %s. Finish the merge with git in %s, or run 'wtree merge --abort'", sess.ID, state.Target, state.Dir)
+	}
+	head, err := git.RevParse(state.Target)
+	if err != nil {
+		return err
+	}
+	if head == state.TargetHead {
+		return fmt.Errorf("nothing was committed to %s since the merge started. Stage the resolution in %s and run 'wtree merge --continue' again, or 'wtree merge --abort'", state.Target, state.Dir)
+	}
+
+	if merged, _ := git.IsMerged(state.Target, sess.Branch); merged || git.ContainsResolved(state.Target, sess.Branch) {
+		return nil
+	}
+
+	// The squash commit is there, so the merge itself is over
+	if state.Temporary {
+		if err := git.RemoveWorktree(state.Dir, true); err != nil {
+			fmt.Printf("Warning: failed to remove temporary worktree: %v\n", err)
+		}
+	}
+	if err := clearPendingMerge(store, sess.ID); err != nil {
+		return err
+	}
+	fmt.Printf("Worktree kept at: %s\n", sess.Path)
+	return fmt.Errorf("%s does not contain all changes of %s, so the branch was kept. Check the result, then remove the worktree with 'wtree rm %s' (it stays restorable from the trash)", state.Target, sess.Branch, sess.ID)
+}
+
+// clearPendingMerge removes the recorded merge state from a session
+func clearPendingMerge(store *session.Store, id string) error {
+	if err := store.Update(func(s *session.Store) error {
+		if current, ok := s.Get(id); ok {
+			current.PendingMerge = nil
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to update sessions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/satoruhiga/wtree/internal/config"
+	"github.com/satoruhiga/wtree/internal/git"
+	"github.com/satoruhiga/wtree/internal/session"
+	"github.com/satoruhiga/wtree/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade config files and sessions.json to the current format",
+	Long: `Upgrade config files and .wtree/sessions.json written by older versions of
+wtree. Older files are already upgraded in memory whenever wtree reads them;
+this command rewrites them on disk. Comments in config files are kept.
+
+Examples:
+  wtree migrate --dry-run   # Show the changes as a diff
+  wtree migrate`,
+	Args: cobra.NoArgs,
+	RunE: runMigrate,
+}
+
+var migrateDryRun bool
+
+func init() {
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Show the changes without writing them")
+	rootCmd.AddCommand(migrateCmd)
+}
+
+func runMigrate(cmd *cobra.Command, args []string) error {
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	gray := color.New(color.FgHiBlack).SprintFunc()
+
+	changed := 0
+	report := func(name, before, after string, applied []string) {
+		if len(applied) == 0 {
+			return
+		}
+		changed++
+		if migrateDryRun {
+			ui.PrintDiff(name, before, after)
+			fmt.Println()
+			return
+		}
+		fmt.Printf("%s Migrated %s\n", green("✓"), name)
+		for _, description := range applied {
+			fmt.Println(gray("  - " + description))
+		}
+	}
+
+	for _, path := range config.Paths(repoRoot) {
+		before, after, applied, err := config.MigrateFile(path, migrateDryRun)
+		if err != nil {
+			return err
+		}
+		report(config.DisplayPath(repoRoot, path), before, after, applied)
+	}
+
+	store := session.NewStore(repoRoot)
+	before, after, applied, err := store.Migrate(migrateDryRun)
+	if err != nil {
+		return err
+	}
+	report(".wtree/sessions.json", string(before), string(after), applied)
+
+	switch {
+	case changed == 0:
+		fmt.Println("Everything is up to date.")
+	case migrateDryRun:
+		fmt.Printf("Would migrate %d file(s). Run without --dry-run to apply.\n", changed)
+	}
+	return nil
+}
@@ -2,17 +2,20 @@ package cmd
 
 import (
 	"fmt"
-	"io"
 	"os"
-	"os/exec"
+	"path"
 	"path/filepath"
-	"runtime"
+	"slices"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/satoruhiga/wtree/internal/config"
+	"github.com/satoruhiga/wtree/internal/fsutil"
 	"github.com/satoruhiga/wtree/internal/git"
+	"github.com/satoruhiga/wtree/internal/hooks"
 	"github.com/satoruhiga/wtree/internal/id"
 	"github.com/satoruhiga/wtree/internal/session"
+	"github.com/satoruhiga/wtree/internal/shell"
 	"github.com/satoruhiga/wtree/internal/terminal"
 	"github.com/spf13/cobra"
 )
@@ -21,26 +24,39 @@ var newCmd = &cobra.Command{
 	Use:   "new",
 	Short: "Create a new worktree and open in terminal",
 	Long: `Create a new worktree with an 8-character random ID and open it
-in terminal (Windows Terminal on Windows, tmux on macOS/Linux).
+in terminal. The terminal is chosen by terminal.backend in config, or
+auto-detected (tmux, zellij, WezTerm, kitty, Windows Terminal).
 
 Examples:
   wtree new          # Create and open in new tab/window
   wtree new --pane   # Create and open in split pane
   wtree new -q       # Create without opening terminal
-  wtree new -n 3     # Create 3 worktrees at once`,
+  wtree new -n 3     # Create 3 worktrees at once
+  wtree new --name fix-login  # Create with a human-readable name
+  wtree new --from origin/feature-x   # Branch from a remote branch, tag or SHA
+  wtree new --checkout feature-x      # Attach to an existing branch (kept on rm)
+  wtree new --profile backend         # Use the [profile.backend] settings`,
 	RunE: runNew,
 }
 
 var (
-	newPane  bool
-	newQuiet bool
-	newCount int
+	newPane     bool
+	newQuiet    bool
+	newCount    int
+	newName     string
+	newFrom     string
+	newCheckout string
+	newProfile  string
 )
 
 func init() {
 	newCmd.Flags().BoolVar(&newPane, "pane", false, "Open in split pane instead of new tab")
 	newCmd.Flags().BoolVarP(&newQuiet, "quiet", "q", false, "Create worktree without opening terminal")
 	newCmd.Flags().IntVarP(&newCount, "n", "n", 1, "Number of worktrees to create")
+	newCmd.Flags().StringVar(&newName, "name", "", "Human-readable name for the worktree")
+	newCmd.Flags().StringVar(&newFrom, "from", "", "Create the new branch from this ref instead of base_branch")
+	newCmd.Flags().StringVar(&newCheckout, "checkout", "", "Check out an existing branch instead of creating one")
+	newCmd.Flags().StringVar(&newProfile, "profile", "", "Apply the settings of a [profile.<name>] config section")
 	rootCmd.AddCommand(newCmd)
 }
 
@@ -56,6 +72,9 @@ func runNew(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if cfg, err = cfg.WithProfile(newProfile); err != nil {
+		return err
+	}
 
 	// Load sessions
 	store := session.NewStore(repoRoot)
@@ -63,6 +82,35 @@ func runNew(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Validate name
+	if newName != "" {
+		if newCount > 1 {
+			return fmt.Errorf("--name cannot be used when creating more than one worktree")
+		}
+		if err := session.ValidateName(newName); err != nil {
+			return err
+		}
+		if store.NameInUse(newName, "") {
+			return fmt.Errorf("name '%s' is already in use", newName)
+		}
+	}
+
+	// Validate source ref
+	if newFrom != "" && newCheckout != "" {
+		return fmt.Errorf("--from and --checkout cannot be used together")
+	}
+	if newFrom != "" && !git.CommitExists(newFrom) {
+		return fmt.Errorf("ref not found: %s", newFrom)
+	}
+	if newCheckout != "" {
+		if newCount > 1 {
+			return fmt.Errorf("--checkout cannot be used when creating more than one worktree")
+		}
+		if !git.LocalBranchExists(newCheckout) && !git.RemoteBranchExists(newCheckout) {
+			return fmt.Errorf("branch not found: %s", newCheckout)
+		}
+	}
+
 	// Determine terminal mode
 	mode := terminal.ModeTab
 	if newPane {
@@ -73,9 +121,21 @@ func runNew(cmd *cobra.Command, args []string) error {
 		mode = terminal.ModeWindow
 	}
 
+	// Select terminal backend
+	backend, err := terminal.Select(cfg.Terminal.Backend)
+	if err != nil {
+		return err
+	}
+
 	// Create worktrees
+	opts := createOptions{
+		name:     newName,
+		from:     newFrom,
+		checkout: newCheckout,
+		profile:  newProfile,
+	}
 	for i := 0; i < newCount; i++ {
-		if err := createWorktree(repoRoot, cfg, store, mode, newQuiet); err != nil {
+		if err := createWorktree(repoRoot, cfg, store, opts, backend, mode, newQuiet); err != nil {
 			return err
 		}
 	}
@@ -83,7 +143,15 @@ func runNew(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func createWorktree(repoRoot string, cfg *config.Config, store *session.Store, mode terminal.OpenMode, quiet bool) error {
+// createOptions controls how a worktree and its branch are created
+type createOptions struct {
+	name     string // Human-readable name
+	from     string // Ref to create the branch from (default: base_branch)
+	checkout string // Existing branch to check out instead of creating one
+	profile  string // Config profile, already applied to the config
+}
+
+func createWorktree(repoRoot string, cfg *config.Config, store *session.Store, opts createOptions, backend terminal.Backend, mode terminal.OpenMode, quiet bool) error {
 	// Generate ID
 	newID, err := id.Generate()
 	if err != nil {
@@ -92,59 +160,71 @@ func createWorktree(repoRoot string, cfg *config.Config, store *session.Store, m
 
 	// Build paths and names
 	branchName := cfg.Worktree.BranchPrefix + newID
+	if opts.checkout != "" {
+		branchName = opts.checkout
+	} else if opts.name != "" && cfg.Worktree.BranchFromName {
+		branchName = cfg.Worktree.BranchPrefix + opts.name
+	}
 	worktreeRelPath := filepath.Join(cfg.Worktree.WorktreeBaseDir, "wt-"+newID)
 	worktreeAbsPath := filepath.Join(repoRoot, worktreeRelPath)
 
-	// Create worktree
-	if err := git.AddWorktree(worktreeAbsPath, branchName, cfg.Worktree.BaseBranch); err != nil {
+	hookCtx := hooks.Context{
+		RepoRoot:   repoRoot,
+		ID:         newID,
+		Name:       opts.name,
+		Branch:     branchName,
+		Path:       worktreeAbsPath,
+		BaseBranch: cfg.Worktree.BaseBranch,
+		Profile:    opts.profile,
+	}
+	if err := hooks.Run(cfg.Hooks, hooks.PreCreate, hookCtx); err != nil {
 		return err
 	}
 
-	green := color.New(color.FgGreen).SprintFunc()
-	fmt.Printf("Created: %s\n", green(newID))
-
-	// Copy files if configured
-	if len(cfg.Setup.Copy) > 0 {
-		for _, item := range cfg.Setup.Copy {
-			srcPath := filepath.Join(repoRoot, item)
-			dstPath := filepath.Join(worktreeAbsPath, item)
-			if err := copyPath(srcPath, dstPath); err != nil {
-				fmt.Printf("Warning: failed to copy %s: %v\n", item, err)
-			}
+	// Create worktree
+	existingBranch := false
+	if opts.checkout != "" {
+		// A branch git creates from a remote branch of the same name is ours
+		existingBranch = git.LocalBranchExists(opts.checkout)
+		if err := git.AddWorktreeForBranch(worktreeAbsPath, opts.checkout); err != nil {
+			return err
+		}
+	} else {
+		baseRef := cfg.Worktree.BaseBranch
+		if opts.from != "" {
+			baseRef = opts.from
+		}
+		if err := git.AddWorktree(worktreeAbsPath, branchName, baseRef); err != nil {
+			return err
 		}
 	}
 
-	// Run setup commands if configured
-	if len(cfg.Setup.Commands) > 0 {
-		for _, cmdStr := range cfg.Setup.Commands {
-			fmt.Printf("Running: %s\n", cmdStr)
-			var execCmd *exec.Cmd
-			if runtime.GOOS == "windows" {
-				execCmd = exec.Command("cmd", "/c", cmdStr)
-			} else {
-				execCmd = exec.Command("sh", "-c", cmdStr)
-			}
-			execCmd.Dir = worktreeAbsPath
-			execCmd.Stdout = os.Stdout
-			execCmd.Stderr = os.Stderr
-			if err := execCmd.Run(); err != nil {
-				fmt.Printf("Warning: command failed: %v\n", err)
-			}
-		}
+	green := color.New(color.FgGreen).SprintFunc()
+	if opts.name != "" {
+		fmt.Printf("Created: %s (%s)\n", green(newID), opts.name)
+	} else {
+		fmt.Printf("Created: %s\n", green(newID))
 	}
 
+	setupWorktree(repoRoot, cfg, worktreeAbsPath, hookCtx)
+
 	// Save session
 	sess := session.NewSession(newID, branchName, worktreeRelPath, worktreeAbsPath)
-	store.Add(sess)
-	if err := store.Save(); err != nil {
+	sess.Name = opts.name
+	sess.ExistingBranch = existingBranch
+	sess.Profile = opts.profile
+	if err := store.Update(func(s *session.Store) error {
+		s.Add(sess)
+		return nil
+	}); err != nil {
 		return fmt.Errorf("failed to save session: %w", err)
 	}
 
 	// Open in terminal (unless quiet mode)
 	if !quiet {
-		if terminal.IsAvailable() {
-			fmt.Printf("Opening in %s...\n", terminal.TerminalName())
-			if err := terminal.OpenInTerminal(worktreeAbsPath, mode, cfg.Terminal.Exec); err != nil {
+		if backend.IsAvailable() {
+			fmt.Printf("Opening in %s...\n", backend.Name())
+			if err := backend.Open(worktreeAbsPath, mode, cfg.Terminal.Exec); err != nil {
 				fmt.Printf("Warning: failed to open terminal: %v\n", err)
 			}
 		} else {
@@ -155,69 +235,87 @@ func createWorktree(repoRoot string, cfg *config.Config, store *session.Store, m
 	return nil
 }
 
-// copyPath copies a file or directory
-func copyPath(src, dst string) error {
-	srcInfo, err := os.Stat(src)
-	if err != nil {
-		return err
+// setupWorktree copies the [setup] files, runs the setup commands and the
+// post-create hook in a freshly checked out worktree
+func setupWorktree(repoRoot string, cfg *config.Config, worktreeAbsPath string, hookCtx hooks.Context) {
+	// Copy files if configured
+	for _, entry := range cfg.Setup.Copy {
+		if err := copySetupEntry(repoRoot, cfg, entry, worktreeAbsPath); err != nil {
+			fmt.Printf("Warning: failed to copy %s: %v\n", entry.Path, err)
+		}
 	}
 
-	if srcInfo.IsDir() {
-		return copyDir(src, dst)
+	// Run setup commands if configured
+	if len(cfg.Setup.Commands) > 0 {
+		for _, cmdStr := range cfg.Setup.Commands {
+			fmt.Printf("Running: %s\n", cmdStr)
+			execCmd := shell.Command(cmdStr)
+			execCmd.Dir = worktreeAbsPath
+			execCmd.Stdout = os.Stdout
+			execCmd.Stderr = os.Stderr
+			if err := execCmd.Run(); err != nil {
+				fmt.Printf("Warning: command failed: %v\n", err)
+			}
+		}
 	}
-	return copyFile(src, dst)
+
+	runPostHook(cfg, hooks.PostCreate, hookCtx)
 }
 
-func copyFile(src, dst string) error {
-	srcFile, err := os.Open(src)
+// copySetupEntry brings the paths matching a [setup] copy entry from the
+// repository into a worktree
+func copySetupEntry(repoRoot string, cfg *config.Config, entry config.CopyEntry, worktreeAbsPath string) error {
+	mode, err := fsutil.ParseMode(entry.Mode)
 	if err != nil {
 		return err
 	}
-	defer srcFile.Close()
-
-	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
-		return err
-	}
-
-	dstFile, err := os.Create(dst)
+	excluded := setupExclude(cfg, entry)
+	paths, err := fsutil.Glob(repoRoot, entry.Path, excluded)
 	if err != nil {
 		return err
 	}
-	defer dstFile.Close()
 
-	_, err = io.Copy(dstFile, srcFile)
-	return err
+	for _, rel := range paths {
+		opts := fsutil.Options{
+			Mode: mode,
+			Exclude: func(name string) bool {
+				return excluded(path.Join(rel, name))
+			},
+		}
+		src := filepath.Join(repoRoot, filepath.FromSlash(rel))
+		dst := filepath.Join(worktreeAbsPath, filepath.FromSlash(rel))
+		if err := fsutil.Copy(src, dst, opts); err != nil {
+			return err
+		}
+		if mode == fsutil.ModeSymlink {
+			ignoreLink(repoRoot, worktreeAbsPath, rel)
+		}
+	}
+	return nil
 }
 
-func copyDir(src, dst string) error {
-	srcInfo, err := os.Stat(src)
-	if err != nil {
-		return err
+// ignoreLink keeps a symlinked [setup] entry out of git status. A directory
+// pattern such as "node_modules/" ignores the source but not a link to it,
+// so the link is added to info/exclude when its source is ignored.
+func ignoreLink(repoRoot, worktreeAbsPath, rel string) {
+	if git.IsIgnored(worktreeAbsPath, rel) {
+		return
 	}
-
-	if err := os.MkdirAll(dst, srcInfo.Mode()); err != nil {
-		return err
+	if !git.IsIgnored(repoRoot, rel) {
+		fmt.Printf("Warning: %s is not ignored by git and will show up as an untracked file\n", rel)
+		return
 	}
-
-	entries, err := os.ReadDir(src)
-	if err != nil {
-		return err
+	pattern := "/" + strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`).Replace(rel)
+	if err := git.AddExclude(worktreeAbsPath, pattern); err != nil {
+		fmt.Printf("Warning: failed to ignore %s: %v\n", rel, err)
 	}
+}
 
-	for _, entry := range entries {
-		srcPath := filepath.Join(src, entry.Name())
-		dstPath := filepath.Join(dst, entry.Name())
-
-		if entry.IsDir() {
-			if err := copyDir(srcPath, dstPath); err != nil {
-				return err
-			}
-		} else {
-			if err := copyFile(srcPath, dstPath); err != nil {
-				return err
-			}
-		}
+// setupExclude reports whether a path (relative to the repository) is
+// excluded from a [setup] copy entry. The trash is never copied.
+func setupExclude(cfg *config.Config, entry config.CopyEntry) func(name string) bool {
+	matches := fsutil.Matcher(append(slices.Clone(cfg.Setup.Exclude), entry.Exclude...))
+	return func(name string) bool {
+		return name == ".wtree/trash" || matches(name)
 	}
-
-	return nil
 }
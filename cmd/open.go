@@ -12,8 +12,8 @@ import (
 
 var openCmd = &cobra.Command{
 	Use:   "open <id>",
-	Short: "Open an existing worktree in Windows Terminal",
-	Long: `Open an existing worktree in Windows Terminal.
+	Short: "Open an existing worktree in terminal",
+	Long: `Open an existing worktree in the configured terminal.
 The ID can be a partial match (e.g., 'a3f8' for 'a3f8c2d1').
 
 Examples:
@@ -56,6 +56,7 @@ func runOpen(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	cfg = sessionConfig(cfg, sess)
 
 	// Determine terminal mode
 	mode := terminal.ModeTab
@@ -67,14 +68,20 @@ func runOpen(cmd *cobra.Command, args []string) error {
 		mode = terminal.ModeWindow
 	}
 
-	// Open in Windows Terminal
-	if terminal.IsAvailable() {
-		fmt.Printf("Opening %s in Windows Terminal...\n", sess.ID)
-		if err := terminal.OpenInTerminal(sess.AbsPath, mode, cfg.Terminal.Exec); err != nil {
+	// Select terminal backend
+	backend, err := terminal.Select(cfg.Terminal.Backend)
+	if err != nil {
+		return err
+	}
+
+	// Open in terminal
+	if backend.IsAvailable() {
+		fmt.Printf("Opening %s in %s...\n", sess.ID, backend.Name())
+		if err := backend.Open(sess.AbsPath, mode, cfg.Terminal.Exec); err != nil {
 			return fmt.Errorf("failed to open terminal: %w", err)
 		}
 	} else {
-		fmt.Printf("Windows Terminal not found.\nPath: %s\n", sess.AbsPath)
+		fmt.Printf("Path: %s\n", sess.AbsPath)
 	}
 
 	return nil
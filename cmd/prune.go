@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/satoruhiga/wtree/internal/config"
 	"github.com/satoruhiga/wtree/internal/git"
+	"github.com/satoruhiga/wtree/internal/hooks"
 	"github.com/satoruhiga/wtree/internal/session"
 	"github.com/satoruhiga/wtree/internal/ui"
 	"github.com/spf13/cobra"
@@ -19,26 +22,68 @@ var pruneCmd = &cobra.Command{
 	Long: `Remove all merged worktrees and clean up empty directories in the worktree base directory.
 
 This command:
-1. Removes all worktrees that have been merged to the base branch
-2. Runs 'git worktree prune' to clean up stale entries
-3. Removes empty directories in the worktree base directory
+1. Removes all worktrees that have been merged to the base branch, including
+   branches that were squash-merged or rebase-merged (e.g. on a forge)
+2. Removes worktrees matched by the [prune] policies in config:
+   remove_clean_after - clean worktrees inactive for this long
+   max_age            - any worktree inactive for this long (branches with
+                        unmerged commits are kept)
+   Worktrees with uncommitted changes are never removed by a policy.
+Locked worktrees (wtree lock) are kept unless keep_if_locked = false or
+--ignore-lock is given.
+3. Runs 'git worktree prune' to clean up stale entries
+4. Removes empty directories in the worktree base directory
+
+Removed worktrees go to the trash and can be brought back with
+'wtree restore <id>' (see 'wtree trash').
 
 Use this after merging via GUI tools (Fork, etc.) or when worktree directories
 couldn't be removed due to locked files.
 
 Examples:
-  wtree prune          # Clean up with confirmation
-  wtree prune --force  # Skip confirmation`,
+  wtree prune            # Clean up with confirmation
+  wtree prune --dry-run  # Show what each rule would remove and why
+  wtree prune --force    # Skip confirmation`,
 	RunE: runPrune,
 }
 
-var pruneForce bool
+var (
+	pruneForce      bool
+	pruneDryRun     bool
+	pruneIgnoreLock bool
+)
 
 func init() {
 	pruneCmd.Flags().BoolVarP(&pruneForce, "force", "f", false, "Skip confirmation")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Show what would be removed without removing anything")
+	pruneCmd.Flags().BoolVar(&pruneIgnoreLock, "ignore-lock", false, "Also remove locked worktrees")
 	rootCmd.AddCommand(pruneCmd)
 }
 
+// Prune rules, named after their config keys
+const (
+	pruneRuleMerged     = "merged"
+	pruneRuleCleanAfter = "remove_clean_after"
+	pruneRuleMaxAge     = "max_age"
+)
+
+// pruneCandidate is a worktree selected for removal by a prune rule
+type pruneCandidate struct {
+	sess        *session.Session
+	cfg         *config.Config // With the session's profile applied
+	rule        string
+	reason      string
+	locked      bool
+	keepBranch  bool // Branch has unmerged commits or no longer exists
+	forceBranch bool // Branch is contained in base but not an ancestor of HEAD (git branch -d would refuse)
+}
+
+// prunePolicy holds the parsed [prune] durations; zero disables a rule
+type prunePolicy struct {
+	maxAge     time.Duration
+	cleanAfter time.Duration
+}
+
 func runPrune(cmd *cobra.Command, args []string) error {
 	// Get repository root
 	repoRoot, err := git.GetRepoRoot()
@@ -58,43 +103,93 @@ func runPrune(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	var policy prunePolicy
+	if cfg.Prune.MaxAge != "" {
+		if policy.maxAge, err = config.ParseDuration(cfg.Prune.MaxAge); err != nil {
+			return fmt.Errorf("invalid prune.max_age: %w", err)
+		}
+	}
+	if cfg.Prune.RemoveCleanAfter != "" {
+		if policy.cleanAfter, err = config.ParseDuration(cfg.Prune.RemoveCleanAfter); err != nil {
+			return fmt.Errorf("invalid prune.remove_clean_after: %w", err)
+		}
+	}
+
 	green := color.New(color.FgGreen).SprintFunc()
 	yellow := color.New(color.FgYellow).SprintFunc()
+	gray := color.New(color.FgHiBlack).SprintFunc()
 
-	// Find merged worktrees
-	var mergedSessions []*session.Session
-	for _, sess := range store.All() {
-		statusInfo, err := git.GetStatus(sess.AbsPath, cfg.Worktree.BaseBranch, sess.Branch)
-		if err != nil {
+	// Find worktrees to remove, each checked against its own base branch
+	snap, err := git.NewSnapshot(cfg.Worktree.BaseBranch)
+	if err != nil {
+		return err
+	}
+	sessions := store.All()
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	statuses, err := sessionStatuses(cfg, sessions, func(baseBranch string) (*git.Snapshot, error) {
+		if baseBranch == cfg.Worktree.BaseBranch {
+			return snap, nil
+		}
+		return git.NewSnapshot(baseBranch)
+	})
+	if err != nil {
+		return err
+	}
+	var candidates, keptLocked []pruneCandidate
+	now := time.Now()
+	for _, st := range statuses {
+		candidate, ok := pruneCandidateFor(st.cfg, st.snap, policy, st.sess, st.result, now)
+		if !ok {
 			continue
 		}
-		if statusInfo.Status == git.StatusMerged {
-			mergedSessions = append(mergedSessions, sess)
+		if candidate.locked && cfg.Prune.KeepLocked() && !pruneIgnoreLock {
+			keptLocked = append(keptLocked, candidate)
+			continue
 		}
+		candidates = append(candidates, candidate)
 	}
 
 	// Report what will be done
-	if len(mergedSessions) > 0 {
-		fmt.Printf("Found %d merged worktree(s):\n", len(mergedSessions))
-		for _, sess := range mergedSessions {
-			fmt.Printf("  - %s (%s)\n", sess.ID, sess.Branch)
+	if len(candidates) > 0 {
+		if pruneDryRun {
+			fmt.Printf("Would remove %d worktree(s):\n", len(candidates))
+		} else {
+			fmt.Printf("Found %d worktree(s) to remove:\n", len(candidates))
+		}
+		for _, c := range candidates {
+			fmt.Printf("  - %s (%s) %s: %s\n", c.sess.ID, c.sess.Branch, c.rule, c.reason)
+		}
+	}
+	if len(keptLocked) > 0 {
+		fmt.Printf("Keeping %d locked worktree(s):\n", len(keptLocked))
+		for _, c := range keptLocked {
+			fmt.Println(gray(fmt.Sprintf("  - %s (%s) %s: %s", c.sess.ID, c.sess.Branch, c.rule, c.reason)))
 		}
 	}
 
 	// Check for empty/orphan directories
 	worktreeBaseDir := filepath.Join(repoRoot, cfg.Worktree.WorktreeBaseDir)
-	emptyDirs := findEmptyOrOrphanDirs(worktreeBaseDir, store)
+	emptyDirs := findEmptyOrOrphanDirs(worktreeBaseDir, store, snap)
 	if len(emptyDirs) > 0 {
-		fmt.Printf("Found %d empty/orphan directory(ies):\n", len(emptyDirs))
+		if pruneDryRun {
+			fmt.Printf("Would remove %d empty/orphan directory(ies):\n", len(emptyDirs))
+		} else {
+			fmt.Printf("Found %d empty/orphan directory(ies):\n", len(emptyDirs))
+		}
 		for _, dir := range emptyDirs {
 			fmt.Printf("  - %s\n", filepath.Base(dir))
 		}
 	}
 
-	if len(mergedSessions) == 0 && len(emptyDirs) == 0 {
+	if len(candidates) == 0 && len(emptyDirs) == 0 {
 		fmt.Println("Nothing to clean up.")
 		return nil
 	}
+	if pruneDryRun {
+		return nil
+	}
 
 	// Confirm
 	if !pruneForce {
@@ -104,28 +199,64 @@ func runPrune(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Remove merged worktrees
-	for _, sess := range mergedSessions {
+	// Remove worktrees
+	var removed []pruneCandidate
+	for _, c := range candidates {
+		sess := c.sess
+		if err := hooks.Run(c.cfg.Hooks, hooks.PreRemove, sessionHookContext(repoRoot, c.cfg, sess)); err != nil {
+			fmt.Printf("%s Skipping %s: %v\n", yellow("!"), sess.ID, err)
+			continue
+		}
+
+		// Keep the branch and uncommitted changes restorable
+		if _, err := trashSession(repoRoot, c.cfg, sess, true); err != nil {
+			fmt.Printf("%s Skipping %s: %v\n", yellow("!"), sess.ID, err)
+			continue
+		}
+
+		closeTerminals(c.cfg, sess.AbsPath)
+
+		// Locked worktrees only get here with keep_if_locked = false or --ignore-lock
+		if c.locked {
+			if err := releaseLock(sess); err != nil {
+				fmt.Printf("%s %v\n", yellow("!"), err)
+			}
+		}
+
 		// Try to remove worktree
 		if err := git.RemoveWorktree(sess.AbsPath, true); err != nil {
 			fmt.Printf("%s Failed to remove worktree %s: %v\n", yellow("!"), sess.ID, err)
 		}
 
-		// Delete branch
-		if err := git.DeleteBranch(sess.Branch, false); err != nil {
-			fmt.Printf("%s Failed to delete branch %s: %v\n", yellow("!"), sess.Branch, err)
+		// Delete branch (unless wtree attached to an existing one)
+		switch {
+		case !sess.OwnsBranch():
+		case c.keepBranch:
+			fmt.Println(gray("Kept branch " + sess.Branch))
+		default:
+			if err := git.DeleteBranch(sess.Branch, c.forceBranch); err != nil {
+				fmt.Printf("%s Failed to delete branch %s: %v\n", yellow("!"), sess.Branch, err)
+			}
 		}
 
-		// Remove from sessions
-		store.Remove(sess.ID)
+		removed = append(removed, c)
 		fmt.Printf("%s Removed %s\n", green("✓"), sess.ID)
 	}
 
-	// Save sessions
-	if err := store.Save(); err != nil {
+	// Remove from sessions
+	if err := store.Update(func(s *session.Store) error {
+		for _, c := range removed {
+			s.Remove(c.sess.ID)
+		}
+		return nil
+	}); err != nil {
 		return fmt.Errorf("failed to update sessions: %w", err)
 	}
 
+	for _, c := range removed {
+		runPostHook(c.cfg, hooks.PostRemove, sessionHookContext(repoRoot, c.cfg, c.sess))
+	}
+
 	// Run git worktree prune
 	if err := git.PruneWorktrees(); err != nil {
 		fmt.Printf("%s git worktree prune failed: %v\n", yellow("!"), err)
@@ -145,8 +276,68 @@ func runPrune(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// pruneCandidateFor returns the first prune rule that applies to a session.
+// Stale sessions and worktrees with work in progress never match.
+func pruneCandidateFor(cfg *config.Config, snap *git.Snapshot, policy prunePolicy, sess *session.Session, result git.StatusResult, now time.Time) (pruneCandidate, bool) {
+	// A pending merge is finished or aborted with wtree merge
+	if result.Err != nil || sess.PendingMerge != nil {
+		return pruneCandidate{}, false
+	}
+	info := result.Info
+
+	// Inactive since the later of creation and the branch's last commit
+	lastActive := sess.CreatedAt
+	if t := snap.LastCommitTime(sess.Branch); t.After(lastActive) {
+		lastActive = t
+	}
+	inactive := now.Sub(lastActive)
+
+	c := pruneCandidate{sess: sess, cfg: cfg, locked: info.Locked, keepBranch: info.BranchMissing}
+	clean := info.Status == git.StatusClean || info.Status == git.StatusBehind
+	switch {
+	case info.Status == git.StatusMerged:
+		c.rule = pruneRuleMerged
+		c.reason = "merged into " + cfg.Worktree.BaseBranch
+		if info.MergedBy != "" {
+			// Squash- and rebase-merged branches aren't ancestors of base
+			c.reason += " (" + info.MergedBy + ")"
+			c.forceBranch = true
+		}
+	case info.Status == git.StatusStale || info.Status == git.StatusUncommitted:
+		return pruneCandidate{}, false
+	case clean && policy.cleanAfter > 0 && inactive > policy.cleanAfter:
+		c.rule = pruneRuleCleanAfter
+		c.reason = fmt.Sprintf("clean, inactive for %s (limit %s)", formatInactive(inactive), cfg.Prune.RemoveCleanAfter)
+		c.forceBranch = true
+	case policy.maxAge > 0 && inactive > policy.maxAge:
+		c.rule = pruneRuleMaxAge
+		c.reason = fmt.Sprintf("inactive for %s (limit %s)", formatInactive(inactive), cfg.Prune.MaxAge)
+		if info.AheadCount > 0 {
+			c.reason += fmt.Sprintf(", keeping branch with %d unmerged commit(s)", info.AheadCount)
+			c.keepBranch = true
+		} else {
+			c.forceBranch = true
+		}
+	default:
+		return pruneCandidate{}, false
+	}
+	return c, true
+}
+
+// formatInactive formats a duration in the largest whole unit
+func formatInactive(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%d days", int(d.Hours()/24))
+	case d >= 2*time.Hour:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d mins", int(d.Minutes()))
+	}
+}
+
 // findEmptyOrOrphanDirs finds directories in worktreeBaseDir that are empty or not tracked in sessions
-func findEmptyOrOrphanDirs(worktreeBaseDir string, store *session.Store) []string {
+func findEmptyOrOrphanDirs(worktreeBaseDir string, store *session.Store, snap *git.Snapshot) []string {
 	var result []string
 
 	entries, err := os.ReadDir(worktreeBaseDir)
@@ -177,7 +368,7 @@ func findEmptyOrOrphanDirs(worktreeBaseDir string, store *session.Store) []strin
 		}
 
 		// Check if it's a valid git worktree
-		if git.WorktreeExists(absPath) {
+		if snap.WorktreeExists(absPath) {
 			continue
 		}
 
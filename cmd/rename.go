@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/satoruhiga/wtree/internal/git"
+	"github.com/satoruhiga/wtree/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var renameCmd = &cobra.Command{
+	Use:   "rename <id> <name>",
+	Short: "Set the name of a worktree",
+	Long: `Set a human-readable name for a worktree. The name can be used anywhere
+an ID is accepted. Pass an empty name to remove it.
+
+The branch is not renamed.
+
+Examples:
+  wtree rename a3f8 fix-login   # Name worktree a3f8 "fix-login"
+  wtree open fix-login          # Open it by name
+  wtree rename fix-login ""     # Remove the name`,
+	Args: cobra.ExactArgs(2),
+	RunE: runRename,
+}
+
+func init() {
+	rootCmd.AddCommand(renameCmd)
+}
+
+func runRename(cmd *cobra.Command, args []string) error {
+	partialID := args[0]
+	name := args[1]
+
+	if name != "" {
+		if err := session.ValidateName(name); err != nil {
+			return err
+		}
+	}
+
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	// Update session
+	store := session.NewStore(repoRoot)
+	var id string
+	if err := store.Update(func(s *session.Store) error {
+		sess, err := s.FindByPartialID(partialID)
+		if err != nil {
+			return err
+		}
+		if name != "" && s.NameInUse(name, sess.ID) {
+			return fmt.Errorf("name '%s' is already in use", name)
+		}
+		sess.Name = name
+		id = sess.ID
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	if name == "" {
+		fmt.Printf("%s Removed name of %s\n", green("✓"), id)
+	} else {
+		fmt.Printf("%s Renamed %s to %s\n", green("✓"), id, name)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/satoruhiga/wtree/internal/config"
+	"github.com/satoruhiga/wtree/internal/git"
+	"github.com/satoruhiga/wtree/internal/hooks"
+	"github.com/satoruhiga/wtree/internal/session"
+	"github.com/satoruhiga/wtree/internal/trash"
+	"github.com/spf13/cobra"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "Restore a removed worktree from the trash",
+	Long: `Recreate a worktree removed by 'wtree rm' or 'wtree prune' at its old path.
+
+The branch is recreated at the commit it pointed to when it was removed, and
+uncommitted changes and saved untracked files are put back. [setup] copies
+and commands run as for 'wtree new', before the saved files are restored.
+
+Examples:
+  wtree trash ls       # Find the ID
+  wtree restore a3f8`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRestore,
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	partialID := args[0]
+
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	// Load configuration
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	// Load sessions
+	store := session.NewStore(repoRoot)
+	if err := store.Load(); err != nil {
+		return err
+	}
+
+	entry, err := trash.Find(repoRoot, partialID)
+	if err != nil {
+		return err
+	}
+	sess := entry.Session
+	if _, ok := store.Get(sess.ID); ok {
+		return fmt.Errorf("worktree %s already exists", sess.ID)
+	}
+	if _, err := os.Stat(sess.AbsPath); err == nil {
+		return fmt.Errorf("path already exists: %s", sess.AbsPath)
+	}
+	if entry.Head == "" {
+		return fmt.Errorf("trashed worktree %s has no commit to restore", sess.ID)
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	if sess.Name != "" && store.NameInUse(sess.Name, sess.ID) {
+		fmt.Printf("%s Name '%s' is now in use, restoring without a name\n", yellow("Warning:"), sess.Name)
+		sess.Name = ""
+	}
+	// Merge state and locks belonged to the removed checkout
+	if state := sess.PendingMerge; state != nil {
+		fmt.Printf("%s The unfinished merge into %s is not restored. Run 'wtree merge' again if needed\n", yellow("Warning:"), state.Target)
+		sess.PendingMerge = nil
+	}
+	sess.Lock = nil
+
+	cfg = sessionConfig(cfg, sess)
+	hookCtx := sessionHookContext(repoRoot, cfg, sess)
+	if err := hooks.Run(cfg.Hooks, hooks.PreCreate, hookCtx); err != nil {
+		return err
+	}
+
+	// Recreate the worktree, reusing the branch if it still exists
+	if git.LocalBranchExists(sess.Branch) {
+		if head, err := git.RevParse("refs/heads/" + sess.Branch); err == nil && head != entry.Head {
+			fmt.Printf("%s Branch %s has moved since it was removed, checking out its current head\n", yellow("Warning:"), sess.Branch)
+		}
+		if err := git.AddWorktreeForBranch(sess.AbsPath, sess.Branch); err != nil {
+			return err
+		}
+	} else {
+		if err := git.AddWorktree(sess.AbsPath, sess.Branch, entry.Head); err != nil {
+			return err
+		}
+	}
+
+	setupWorktree(repoRoot, cfg, sess.AbsPath, hookCtx)
+
+	if err := trash.RestoreFiles(repoRoot, entry, sess.AbsPath); err != nil {
+		fmt.Printf("%s %v\n", yellow("Warning:"), err)
+	}
+
+	// A failed apply leaves the changes in the trash, so keep the entry
+	keepEntry := false
+	if entry.Changes != "" {
+		if err := git.StashApply(sess.AbsPath, entry.Changes); err != nil {
+			fmt.Printf("%s %v\n", yellow("Warning:"), err)
+			fmt.Printf("  The changes are kept in %s until 'wtree trash empty %s'\n", trash.ChangesRef(sess.ID), sess.ID)
+			keepEntry = true
+		}
+	}
+
+	// Save session
+	if err := store.Update(func(s *session.Store) error {
+		s.Add(sess)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+
+	if !keepEntry {
+		if err := trash.Delete(repoRoot, sess.ID); err != nil {
+			fmt.Printf("%s %v\n", yellow("Warning:"), err)
+		}
+	}
+
+	fmt.Printf("%s Restored %s\n", green("✓"), sess.ID)
+	fmt.Printf("Path: %s\n", sess.AbsPath)
+	return nil
+}
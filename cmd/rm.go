@@ -6,6 +6,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/satoruhiga/wtree/internal/config"
 	"github.com/satoruhiga/wtree/internal/git"
+	"github.com/satoruhiga/wtree/internal/hooks"
 	"github.com/satoruhiga/wtree/internal/session"
 	"github.com/satoruhiga/wtree/internal/ui"
 	"github.com/spf13/cobra"
@@ -15,7 +16,13 @@ var rmCmd = &cobra.Command{
 	Use:   "rm <id>",
 	Short: "Remove a worktree",
 	Long: `Remove a worktree and its associated branch.
+Branches that existed before the worktree (wtree new --checkout) are kept.
 Shows a warning if there are uncommitted or unmerged changes.
+Locked worktrees (wtree lock) are refused unless --ignore-lock is given.
+Worktrees with an unfinished merge are refused unless --force is given,
+which aborts the merge first.
+The removed worktree goes to the trash and can be brought back with
+'wtree restore <id>' (see 'wtree trash').
 
 Examples:
   wtree rm a3f8         # Remove with confirmation
@@ -24,10 +31,14 @@ Examples:
 	RunE: runRm,
 }
 
-var rmForce bool
+var (
+	rmForce      bool
+	rmIgnoreLock bool
+)
 
 func init() {
 	rmCmd.Flags().BoolVarP(&rmForce, "force", "f", false, "Skip confirmation")
+	rmCmd.Flags().BoolVar(&rmIgnoreLock, "ignore-lock", false, "Remove the worktree even if it is locked")
 	rootCmd.AddCommand(rmCmd)
 }
 
@@ -57,50 +68,88 @@ func runRm(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if err := checkNotLocked(sess, rmIgnoreLock); err != nil {
+		return err
+	}
+	cfg = sessionConfig(cfg, sess)
 
 	green := color.New(color.FgGreen).SprintFunc()
 	yellow := color.New(color.FgYellow).SprintFunc()
 
+	// An unfinished merge would be left behind in its directory
+	if state := sess.PendingMerge; state != nil {
+		if !rmForce {
+			return fmt.Errorf("a merge of %s into %s is in progress. Finish it with 'wtree merge --continue' or 'wtree merge --abort', or use --force to abort it", sess.Branch, state.Target)
+		}
+		if err := undoPendingMerge(store, sess); err != nil {
+			return err
+		}
+		fmt.Printf("%s Aborted merge of %s into %s\n", yellow("!"), sess.Branch, state.Target)
+	}
+
 	// Check if worktree still exists
 	worktreeExists := git.WorktreeExists(sess.AbsPath)
 
-	if worktreeExists {
-		// Check status and warn if necessary
-		if !rmForce {
-			statusInfo, err := git.GetStatus(sess.AbsPath, cfg.Worktree.BaseBranch, sess.Branch)
-			if err == nil {
-				switch statusInfo.Status {
-				case git.StatusUncommitted:
-					fmt.Printf("%s %s has uncommitted changes.\n", yellow("Warning:"), sess.ID)
-					if !ui.Confirm("Continue?") {
-						fmt.Println("Cancelled.")
-						return nil
-					}
-				case git.StatusAhead:
-					fmt.Printf("%s %s has %d unmerged commits.\n", yellow("Warning:"), sess.ID, statusInfo.AheadCount)
-					if !ui.Confirm("Continue?") {
-						fmt.Println("Cancelled.")
-						return nil
-					}
+	// Check status and warn if necessary
+	if worktreeExists && !rmForce {
+		statusInfo, err := git.GetStatus(sess.AbsPath, cfg.Worktree.BaseBranch, sess.Branch)
+		if err == nil {
+			switch statusInfo.Status {
+			case git.StatusUncommitted:
+				fmt.Printf("%s %s has uncommitted changes.\n", yellow("Warning:"), sess.ID)
+				if !ui.Confirm("Continue?") {
+					fmt.Println("Cancelled.")
+					return nil
+				}
+			case git.StatusAhead, git.StatusDiverged:
+				// Commits on an existing branch survive removal
+				if !sess.OwnsBranch() {
+					break
+				}
+				fmt.Printf("%s %s has %d unmerged commits.\n", yellow("Warning:"), sess.ID, statusInfo.AheadCount)
+				if !ui.Confirm("Continue?") {
+					fmt.Println("Cancelled.")
+					return nil
 				}
 			}
 		}
+	}
+
+	hookCtx := sessionHookContext(repoRoot, cfg, sess)
+	if err := hooks.Run(cfg.Hooks, hooks.PreRemove, hookCtx); err != nil {
+		return err
+	}
+
+	// Keep the branch and uncommitted changes restorable
+	trashed, err := trashSession(repoRoot, cfg, sess, worktreeExists)
+	if err != nil {
+		return err
+	}
+
+	if worktreeExists {
+		closeTerminals(cfg, sess.AbsPath)
+
+		if err := releaseLock(sess); err != nil {
+			fmt.Printf("%s %v\n", yellow("Warning:"), err)
+		}
 
 		// Remove worktree
 		if err := git.RemoveWorktree(sess.AbsPath, rmForce); err != nil {
 			fmt.Printf("%s Failed to remove worktree: %v\n", yellow("Warning:"), err)
 		}
 
-		// Delete branch
-		if err := git.DeleteBranch(sess.Branch, rmForce); err != nil {
-			fmt.Printf("%s Failed to delete branch %s: %v\n", yellow("Warning:"), sess.Branch, err)
+		// Delete branch (unless wtree attached to an existing one)
+		if sess.OwnsBranch() {
+			if err := git.DeleteBranch(sess.Branch, rmForce); err != nil {
+				fmt.Printf("%s Failed to delete branch %s: %v\n", yellow("Warning:"), sess.Branch, err)
+			}
 		}
 	} else {
 		// Worktree doesn't exist, just clean up session and try to delete branch
 		fmt.Printf("Worktree %s no longer exists, cleaning up session...\n", sess.ID)
 
 		// Try to delete branch anyway (it might still exist)
-		if git.BranchExists(sess.Branch) {
+		if sess.OwnsBranch() && git.BranchExists(sess.Branch) {
 			if err := git.DeleteBranch(sess.Branch, true); err != nil {
 				fmt.Printf("%s Failed to delete branch %s: %v\n", yellow("Warning:"), sess.Branch, err)
 			}
@@ -108,12 +157,20 @@ func runRm(cmd *cobra.Command, args []string) error {
 	}
 
 	// Remove from sessions
-	store.Remove(sess.ID)
-	if err := store.Save(); err != nil {
+	if err := store.Update(func(s *session.Store) error {
+		s.Remove(sess.ID)
+		return nil
+	}); err != nil {
 		return fmt.Errorf("failed to update sessions: %w", err)
 	}
 
 	fmt.Printf("%s Removed %s\n", green("✓"), sess.ID)
+	if trashed {
+		gray := color.New(color.FgHiBlack).SprintFunc()
+		fmt.Println(gray("  Restore with: wtree restore " + sess.ID))
+	}
+
+	runPostHook(cfg, hooks.PostRemove, hookCtx)
 
 	return nil
 }
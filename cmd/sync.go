@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/satoruhiga/wtree/internal/config"
+	"github.com/satoruhiga/wtree/internal/git"
+	"github.com/satoruhiga/wtree/internal/session"
+	"github.com/satoruhiga/wtree/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync [id...]",
+	Short: "Bring worktrees up to date with the base branch",
+	Long: `Rebase (or merge) the base branch into worktrees.
+
+If the base branch has an upstream, it is fetched first and the local base
+branch is fast-forwarded to it. Worktrees with uncommitted changes to
+tracked files are skipped unless --autostash is given. Untracked files are
+left alone, but an update that would overwrite them fails. A rebase or merge
+that conflicts is aborted, leaving the worktree as it was.
+
+Each worktree is reported as updated, up to date, skipped, conflicted or
+failed.
+Exits with a non-zero status if any worktree conflicted or failed.
+
+Examples:
+  wtree sync --all              # Rebase every worktree onto the base branch
+  wtree sync a3f8 fix-login     # Only these worktrees
+  wtree sync --all --merge      # Merge the base branch instead of rebasing
+  wtree sync --all --autostash  # Stash local changes around the update`,
+	RunE: runSync,
+}
+
+var (
+	syncAll       bool
+	syncRebase    bool
+	syncMerge     bool
+	syncAutostash bool
+)
+
+func init() {
+	syncCmd.Flags().BoolVarP(&syncAll, "all", "a", false, "Sync all worktrees")
+	syncCmd.Flags().BoolVar(&syncRebase, "rebase", false, "Rebase onto the base branch (default)")
+	syncCmd.Flags().BoolVar(&syncMerge, "merge", false, "Merge the base branch instead of rebasing")
+	syncCmd.Flags().BoolVar(&syncAutostash, "autostash", false, "Stash uncommitted changes before updating and reapply them after")
+	syncCmd.MarkFlagsMutuallyExclusive("rebase", "merge")
+	rootCmd.AddCommand(syncCmd)
+}
+
+// syncResult is the outcome of syncing one worktree
+type syncResult struct {
+	sess    *session.Session
+	outcome string
+	failed  bool
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	if syncAll && len(args) > 0 {
+		return fmt.Errorf("cannot combine worktree IDs with --all")
+	}
+	if !syncAll && len(args) == 0 {
+		return fmt.Errorf("specify worktree IDs or --all")
+	}
+
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	// Load configuration
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	// Load sessions
+	store := session.NewStore(repoRoot)
+	if err := store.Load(); err != nil {
+		return err
+	}
+
+	// Select target worktrees
+	var targets []*session.Session
+	if syncAll {
+		targets = store.All()
+		sort.Slice(targets, func(i, j int) bool {
+			return targets[i].CreatedAt.Before(targets[j].CreatedAt)
+		})
+	} else {
+		seen := make(map[string]bool)
+		for _, partialID := range args {
+			sess, err := store.FindByPartialID(partialID)
+			if err != nil {
+				return err
+			}
+			if !seen[sess.ID] {
+				seen[sess.ID] = true
+				targets = append(targets, sess)
+			}
+		}
+	}
+	if len(targets) == 0 {
+		fmt.Println("No worktrees found.")
+		return nil
+	}
+
+	// Each base branch is brought up to date once
+	onto := make(map[string]string)
+	statuses, err := sessionStatuses(cfg, targets, func(baseBranch string) (*git.Snapshot, error) {
+		onto[baseBranch] = syncBase(baseBranch)
+		return git.NewSnapshot(onto[baseBranch])
+	})
+	if err != nil {
+		return err
+	}
+
+	results := make([]syncResult, len(statuses))
+	for i, st := range statuses {
+		results[i] = syncWorktree(st.sess, st.result, onto[st.cfg.Worktree.BaseBranch])
+	}
+
+	// Print summary
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	gray := color.New(color.FgHiBlack).SprintFunc()
+
+	fmt.Println()
+	headers := []string{"ID", "NAME", "BRANCH", "RESULT"}
+	var rows [][]string
+	failed := 0
+	for _, result := range results {
+		var outcome string
+		switch {
+		case result.failed:
+			failed++
+			outcome = red(result.outcome)
+		case result.outcome == "up to date":
+			outcome = gray(result.outcome)
+		case result.outcome == "updated":
+			outcome = green(result.outcome)
+		default:
+			outcome = yellow(result.outcome)
+		}
+		rows = append(rows, []string{result.sess.ID, result.sess.Name, result.sess.Branch, outcome})
+	}
+	ui.PrintTable(headers, rows)
+
+	if failed > 0 {
+		exitWithError("could not sync %d of %d worktree(s)", failed, len(results))
+	}
+	return nil
+}
+
+// syncBase fetches the base branch's upstream and fast-forwards the local
+// base branch to it. It returns the ref worktrees should be synced onto: the
+// local base branch, or the upstream if the local branch couldn't be updated.
+func syncBase(baseBranch string) string {
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	gray := color.New(color.FgHiBlack).SprintFunc()
+
+	upstream, ok := git.GetUpstream(baseBranch)
+	if !ok {
+		fmt.Println(gray("No upstream for " + baseBranch + ", syncing with the local branch"))
+		return baseBranch
+	}
+
+	fmt.Printf("Fetching %s...\n", upstream.Ref)
+	if err := git.Fetch(upstream.Remote, upstream.RemoteRef); err != nil {
+		fmt.Printf("%s %v\n", yellow("Warning:"), err)
+		return baseBranch
+	}
+	if git.IsAncestor(upstream.Ref, baseBranch) {
+		return baseBranch
+	}
+
+	// In a checkout, git refuses to fast-forward over conflicting local changes
+	var err error
+	if path, checkedOut := git.FindBranchWorktree(baseBranch); checkedOut {
+		err = git.MergeFFOnly(path, upstream.Ref)
+	} else {
+		err = git.FastForwardBranch(baseBranch, upstream.Ref)
+	}
+	if err != nil {
+		fmt.Printf("%s Could not fast-forward %s (%v), syncing onto %s\n", yellow("Warning:"), baseBranch, err, upstream.Ref)
+		return upstream.Ref
+	}
+
+	fmt.Printf("%s Fast-forwarded %s to %s\n", green("✓"), baseBranch, upstream.Ref)
+	return baseBranch
+}
+
+// syncWorktree rebases or merges onto into a single worktree
+func syncWorktree(sess *session.Session, status git.StatusResult, onto string) syncResult {
+	result := syncResult{sess: sess}
+	if status.Err != nil {
+		result.outcome = "failed: " + status.Err.Error()
+		result.failed = true
+		return result
+	}
+
+	info := status.Info
+	switch {
+	case info.Status == git.StatusStale:
+		result.outcome = "skipped (stale)"
+	case info.BranchMissing:
+		result.outcome = "skipped (branch missing)"
+	case info.Detached:
+		result.outcome = "skipped (detached)"
+	case info.Operation != "":
+		result.outcome = "skipped (" + info.Operation + " in progress)"
+	case info.BehindCount == 0:
+		result.outcome = "up to date"
+	// Untracked files only matter if incoming files collide with them,
+	// which git reports when updating
+	case info.HasTrackedChanges() && !syncAutostash:
+		result.outcome = "skipped (dirty)"
+	}
+	if result.outcome != "" {
+		return result
+	}
+
+	stashConflict, err := git.UpdateWorktree(sess.AbsPath, onto, !syncMerge, syncAutostash)
+	switch {
+	case errors.Is(err, git.ErrMergeConflict):
+		result.outcome = "conflicted (aborted)"
+		result.failed = true
+	case errors.Is(err, git.ErrUntrackedOverwritten):
+		result.outcome = "failed (untracked files would be overwritten)"
+		result.failed = true
+	case err != nil:
+		result.outcome = "failed: " + err.Error()
+		result.failed = true
+	case stashConflict:
+		result.outcome = "updated (stashed changes conflict, kept in stash)"
+	default:
+		result.outcome = "updated"
+	}
+	return result
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/satoruhiga/wtree/internal/config"
+	"github.com/satoruhiga/wtree/internal/terminal"
+)
+
+// closeTerminals closes terminal panes opened in a worktree if terminal.close_on_remove is set
+func closeTerminals(cfg *config.Config, path string) {
+	if !cfg.Terminal.CloseOnRemove {
+		return
+	}
+
+	backend, err := terminal.Select(cfg.Terminal.Backend)
+	if err != nil || !backend.IsAvailable() {
+		return
+	}
+
+	if err := backend.Close(path); err != nil {
+		yellow := color.New(color.FgYellow).SprintFunc()
+		fmt.Printf("%s %v\n", yellow("Warning:"), err)
+	}
+}
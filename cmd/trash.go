@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/satoruhiga/wtree/internal/config"
+	"github.com/satoruhiga/wtree/internal/fsutil"
+	"github.com/satoruhiga/wtree/internal/git"
+	"github.com/satoruhiga/wtree/internal/session"
+	"github.com/satoruhiga/wtree/internal/trash"
+	"github.com/satoruhiga/wtree/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List or empty removed worktrees",
+	Long: `'wtree rm' and 'wtree prune' move removed worktrees to the trash in
+.wtree/trash instead of losing them. The branch head is kept under
+refs/wtree/trash/<id> and uncommitted changes under refs/wtree/changes/<id>.
+With [trash] keep_untracked = true, untracked files and the [setup] copy
+files are saved too.
+
+Bring a worktree back with 'wtree restore <id>'.
+
+Examples:
+  wtree trash ls
+  wtree trash empty          # Permanently delete everything in the trash
+  wtree trash empty a3f8     # Permanently delete one entry`,
+}
+
+var trashLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List removed worktrees",
+	Args:  cobra.NoArgs,
+	RunE:  runTrashLs,
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty [id...]",
+	Short: "Permanently delete removed worktrees",
+	RunE:  runTrashEmpty,
+}
+
+var trashEmptyForce bool
+
+func init() {
+	trashEmptyCmd.Flags().BoolVarP(&trashEmptyForce, "force", "f", false, "Skip confirmation")
+	trashCmd.AddCommand(trashLsCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+	rootCmd.AddCommand(trashCmd)
+}
+
+func runTrashLs(cmd *cobra.Command, args []string) error {
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	entries, err := trash.List(repoRoot)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("Trash is empty.")
+		return nil
+	}
+
+	gray := color.New(color.FgHiBlack).SprintFunc()
+
+	headers := []string{"ID", "NAME", "BRANCH", "REMOVED", "HEAD", "KEPT"}
+	var rows [][]string
+	for _, entry := range entries {
+		head := gray("-")
+		if entry.Head != "" {
+			head = entry.Head[:min(8, len(entry.Head))]
+		}
+		rows = append(rows, []string{
+			entry.Session.ID,
+			entry.Session.Name,
+			entry.Session.Branch,
+			entry.TrashedAt.Format("2006-01-02 15:04"),
+			head,
+			describeTrashEntry(entry),
+		})
+	}
+	ui.PrintTable(headers, rows)
+	return nil
+}
+
+// describeTrashEntry summarizes what was kept besides the commits
+func describeTrashEntry(entry *trash.Entry) string {
+	var kept []string
+	if entry.Changes != "" {
+		kept = append(kept, "uncommitted changes")
+	}
+	if n := len(entry.Files); n == 1 {
+		kept = append(kept, "1 file")
+	} else if n > 1 {
+		kept = append(kept, strconv.Itoa(n)+" files")
+	}
+	if len(kept) == 0 {
+		return "commits"
+	}
+	return "commits, " + strings.Join(kept, ", ")
+}
+
+func runTrashEmpty(cmd *cobra.Command, args []string) error {
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	var entries []*trash.Entry
+	if len(args) > 0 {
+		for _, partialID := range args {
+			entry, err := trash.Find(repoRoot, partialID)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+		}
+	} else {
+		if entries, err = trash.List(repoRoot); err != nil {
+			return err
+		}
+	}
+	if len(entries) == 0 {
+		fmt.Println("Trash is empty.")
+		return nil
+	}
+
+	if !trashEmptyForce {
+		fmt.Printf("Permanently delete %d worktree(s) from the trash?\n", len(entries))
+		for _, entry := range entries {
+			fmt.Printf("  - %s (%s)\n", entry.Session.ID, entry.Session.Branch)
+		}
+		if !ui.Confirm("Continue?") {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	for _, entry := range entries {
+		if err := trash.Delete(repoRoot, entry.Session.ID); err != nil {
+			return err
+		}
+		fmt.Printf("%s Deleted %s\n", green("✓"), entry.Session.ID)
+	}
+	return nil
+}
+
+// trashSession moves a session to the trash before its worktree and branch
+// are removed, and returns whether anything was kept
+func trashSession(repoRoot string, cfg *config.Config, sess *session.Session, worktreeExists bool) (bool, error) {
+	var files []string
+	if worktreeExists && cfg.Trash.KeepUntracked {
+		untracked, err := git.UntrackedFiles(sess.AbsPath)
+		if err != nil {
+			return false, err
+		}
+		files = untracked
+
+		// [setup] copy files are usually gitignored, so not in the list above
+		seen := make(map[string]bool)
+		for _, file := range untracked {
+			seen[file] = true
+		}
+		for _, entry := range cfg.Setup.Copy {
+			// Symlinked entries point at the repository, which keeps them
+			if entry.Mode == string(fsutil.ModeSymlink) {
+				continue
+			}
+			matches, err := fsutil.Glob(sess.AbsPath, entry.Path, setupExclude(cfg, entry))
+			if err != nil {
+				continue
+			}
+			for _, item := range matches {
+				if !seen[item] {
+					seen[item] = true
+					files = append(files, item)
+				}
+			}
+		}
+	}
+
+	entry, err := trash.Put(repoRoot, sess, worktreeExists, files)
+	if err != nil {
+		return false, fmt.Errorf("failed to move %s to the trash: %w", sess.ID, err)
+	}
+	return entry != nil, nil
+}
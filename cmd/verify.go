@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/satoruhiga/wtree/internal/config"
+	"github.com/satoruhiga/wtree/internal/git"
+	"github.com/satoruhiga/wtree/internal/session"
+	"github.com/satoruhiga/wtree/internal/verify"
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify <id>",
+	Short: "Run merge.verify commands in a worktree",
+	Long: `Run the commands listed in merge.verify inside a worktree, the same
+check 'wtree merge' runs before merging. With merge.verify_rebase, the commands
+run on a trial rebase onto the base branch in a temporary worktree.
+Otherwise they run in the worktree itself, which must have no uncommitted
+or untracked changes.
+
+The result is stored on the worktree and shown by 'wtree ls'.
+
+Examples:
+  wtree verify a3f8`,
+	Args: cobra.ExactArgs(1),
+	RunE: runVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	partialID := args[0]
+
+	// Get repository root
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	// Load configuration
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.Merge.Verify) == 0 {
+		return fmt.Errorf("merge.verify not configured in .wtree/config.toml")
+	}
+
+	// Load sessions
+	store := session.NewStore(repoRoot)
+	if err := store.Load(); err != nil {
+		return err
+	}
+
+	// Find session by partial ID
+	sess, err := store.FindByPartialID(partialID)
+	if err != nil {
+		return err
+	}
+
+	cfg = sessionConfig(cfg, sess)
+
+	result, err := verifySession(repoRoot, cfg, store, sess, cfg.Worktree.BaseBranch)
+	if err != nil {
+		return err
+	}
+	if !result.Passed {
+		exitWithError("verification failed for %s", sess.ID)
+	}
+	return nil
+}
+
+// verifySession runs merge.verify for a session, stores the result on the
+// session and prints a summary
+func verifySession(repoRoot string, cfg *config.Config, store *session.Store, sess *session.Session, target string) (*session.Verification, error) {
+	commit, err := git.RevParse(sess.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := sess.AbsPath
+	var result *session.Verification
+
+	if cfg.Merge.VerifyRebase {
+		// Trial rebase in a throwaway worktree so the real one is untouched
+		tempPath := filepath.Join(repoRoot, cfg.Worktree.WorktreeBaseDir, "verify-"+sess.ID)
+		if err := git.AddDetachedWorktree(tempPath, commit); err != nil {
+			return nil, err
+		}
+		defer git.RemoveWorktree(tempPath, true)
+
+		fmt.Printf("Rebasing %s onto %s for verification...\n", sess.Branch, target)
+		if err := git.Rebase(tempPath, target); err != nil {
+			if !errors.Is(err, git.ErrMergeConflict) {
+				return nil, err
+			}
+			result = &session.Verification{
+				RunAt:  time.Now(),
+				Steps:  []session.VerifyStep{},
+				Reason: "conflicts with " + target,
+			}
+		}
+		dir = tempPath
+	} else {
+		// The result is recorded for the branch head, so the checkout must match it
+		hasChanges, err := git.HasUncommittedChanges(dir)
+		if err != nil {
+			return nil, err
+		}
+		if hasChanges {
+			return nil, fmt.Errorf("worktree %s has uncommitted changes. Please commit or stash them first", sess.ID)
+		}
+	}
+
+	if result == nil {
+		fmt.Printf("Verifying %s...\n", sess.ID)
+		result = verify.Run(dir, cfg.Merge.Verify, os.Stdout)
+	}
+	result.Commit = commit
+	result.Rebased = cfg.Merge.VerifyRebase
+
+	// Store the result
+	if err := store.Update(func(s *session.Store) error {
+		if current, ok := s.Get(sess.ID); ok {
+			current.Verification = result
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to update sessions: %w", err)
+	}
+	sess.Verification = result
+
+	printVerification(result)
+	return result, nil
+}
+
+// printVerification prints one line per verify step
+func printVerification(result *session.Verification) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	if result.Reason != "" {
+		fmt.Printf("%s Verification failed: %s\n", red("✗"), result.Reason)
+		return
+	}
+	for _, step := range result.Steps {
+		duration := step.Duration.Round(time.Millisecond)
+		if step.ExitCode == 0 {
+			fmt.Printf("%s %s (%s)\n", green("✓"), step.Command, duration)
+		} else {
+			fmt.Printf("%s %s (exit %d, %s)\n", red("✗"), step.Command, step.ExitCode, duration)
+		}
+	}
+}
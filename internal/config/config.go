@@ -4,20 +4,55 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/BurntSushi/toml"
+	"github.com/satoruhiga/wtree/internal/fsutil"
 )
 
 const (
-	worktreeDir = ".wtree"
-	configFile  = "config.toml"
+	worktreeDir     = ".wtree"
+	configFile      = "config.toml"
+	localConfigFile = "config.local.toml"
+	ignoreFile      = ".gitignore"
 )
 
+// ignoreContent keeps everything in .wtree but the shared config out of git
+const ignoreContent = `# Written by wtree. Only config.toml is meant to be committed.
+*
+!config.toml
+`
+
 // Config represents the configuration file structure
 type Config struct {
+	Version  int                `toml:"version"` // File format, see migrations
+	Worktree WorktreeConfig     `toml:"worktree"`
+	Setup    SetupConfig        `toml:"setup"`
+	Terminal TerminalConfig     `toml:"terminal"`
+	Hooks    HooksConfig        `toml:"hooks"`
+	Merge    MergeConfig        `toml:"merge"`
+	Prune    PruneConfig        `toml:"prune"`
+	Trash    TrashConfig        `toml:"trash"`
+	Profiles map[string]Profile `toml:"profile"`
+
+	// profileKeys lists the settings each profile sets, e.g. "setup.commands"
+	profileKeys map[string][]string
+	// sources lists the config files and environment variables loaded
+	sources []string
+}
+
+// Profile overrides worktree, setup, terminal and hooks settings for
+// worktrees created with 'wtree new --profile <name>'. Only the keys set in a
+// [profile.<name>] section are overridden.
+type Profile struct {
 	Worktree WorktreeConfig `toml:"worktree"`
 	Setup    SetupConfig    `toml:"setup"`
 	Terminal TerminalConfig `toml:"terminal"`
+	Hooks    HooksConfig    `toml:"hooks"`
 }
 
 // WorktreeConfig contains worktree-related settings
@@ -25,42 +60,428 @@ type WorktreeConfig struct {
 	WorktreeBaseDir string `toml:"worktree_base_dir"`
 	BranchPrefix    string `toml:"branch_prefix"`
 	BaseBranch      string `toml:"base_branch"`
+	BranchFromName  bool   `toml:"branch_from_name"`
 }
 
 // SetupConfig contains setup-related settings
 type SetupConfig struct {
-	Copy     []string `toml:"copy"`
-	Commands []string `toml:"commands"`
+	Copy     []CopyEntry `toml:"copy"`
+	Exclude  []string    `toml:"exclude"`
+	Commands []string    `toml:"commands"`
+}
+
+// CopyEntry is a path or glob pattern, relative to the repository root, to
+// bring into new worktrees. It is written as a string or as a table such as
+// { path = "node_modules", mode = "symlink" }.
+type CopyEntry struct {
+	Path    string   `toml:"path"`
+	Mode    string   `toml:"mode"`    // See fsutil.ParseMode; empty means copy
+	Exclude []string `toml:"exclude"` // Added to [setup] exclude for this entry
+}
+
+// UnmarshalTOML decodes a string or a table
+func (e *CopyEntry) UnmarshalTOML(data any) error {
+	switch v := data.(type) {
+	case string:
+		e.Path = v
+	case map[string]any:
+		for key, value := range v {
+			var ok bool
+			switch key {
+			case "path":
+				e.Path, ok = value.(string)
+			case "mode":
+				e.Mode, ok = value.(string)
+			case "exclude":
+				var items []any
+				items, ok = value.([]any)
+				for _, item := range items {
+					pattern, isString := item.(string)
+					ok = ok && isString
+					e.Exclude = append(e.Exclude, pattern)
+				}
+			default:
+				return fmt.Errorf("unknown key '%s' in setup.copy entry", key)
+			}
+			if !ok {
+				return fmt.Errorf("invalid %s in setup.copy entry", key)
+			}
+		}
+	default:
+		return fmt.Errorf("setup.copy entries must be strings or tables")
+	}
+
+	if e.Path == "" {
+		return fmt.Errorf("setup.copy entry has no path")
+	}
+	if _, err := fsutil.ParseMode(e.Mode); err != nil {
+		return fmt.Errorf("setup.copy %s: %w", e.Path, err)
+	}
+	return nil
+}
+
+// tomlValue formats the entry as a string, or as an inline table when it has
+// more than a path
+func (e CopyEntry) tomlValue() string {
+	if e.Mode == "" && len(e.Exclude) == 0 {
+		return strconv.Quote(e.Path)
+	}
+	fields := []string{"path = " + strconv.Quote(e.Path)}
+	if e.Mode != "" {
+		fields = append(fields, "mode = "+strconv.Quote(e.Mode))
+	}
+	if len(e.Exclude) > 0 {
+		fields = append(fields, "exclude = "+formatValue(reflect.ValueOf(e.Exclude)))
+	}
+	return "{ " + strings.Join(fields, ", ") + " }"
 }
 
 // TerminalConfig contains terminal-related settings
 type TerminalConfig struct {
-	Mode string `toml:"mode"`
-	Exec string `toml:"exec"`
+	Backend       string `toml:"backend"`
+	Mode          string `toml:"mode"`
+	Exec          string `toml:"exec"`
+	CloseOnRemove bool   `toml:"close_on_remove"`
+}
+
+// HooksConfig contains commands run on worktree lifecycle events
+type HooksConfig struct {
+	PreCreate  []string `toml:"pre_create"`
+	PostCreate []string `toml:"post_create"`
+	PreRemove  []string `toml:"pre_remove"`
+	PostRemove []string `toml:"post_remove"`
+	PreMerge   []string `toml:"pre_merge"`
+	PostMerge  []string `toml:"post_merge"`
+}
+
+// MergeConfig contains merge-related settings
+type MergeConfig struct {
+	Strategy     string   `toml:"strategy"`
+	Verify       []string `toml:"verify"`
+	VerifyRebase bool     `toml:"verify_rebase"`
+}
+
+// PruneConfig contains policies for 'wtree prune'. Durations are strings
+// like "14d", "2w" or "36h"; empty disables the policy.
+type PruneConfig struct {
+	MaxAge           string `toml:"max_age"`
+	RemoveCleanAfter string `toml:"remove_clean_after"`
+	KeepIfLocked     *bool  `toml:"keep_if_locked"`
+}
+
+// KeepLocked reports whether locked worktrees are protected (the default)
+func (p PruneConfig) KeepLocked() bool {
+	return p.KeepIfLocked == nil || *p.KeepIfLocked
+}
+
+// TrashConfig contains settings for the trash that rm and prune move worktrees to
+type TrashConfig struct {
+	KeepUntracked bool `toml:"keep_untracked"`
 }
 
-// Load reads the configuration from config.toml
+// Origins maps each setting (e.g. "terminal.mode") to where its effective
+// value came from: a config file or an environment variable. Settings left at
+// their default are absent.
+type Origins map[string]string
+
+// Load reads the configuration, merging in order ~/.config/wtree/config.toml,
+// .wtree/config.toml, .wtree/config.local.toml and WTREE_* environment
+// variables. Later layers override earlier ones key by key; lists are replaced.
 func Load(repoRoot string) (*Config, error) {
-	configPath := filepath.Join(repoRoot, worktreeDir, configFile)
+	config, _, err := LoadWithOrigins(repoRoot)
+	return config, err
+}
 
-	data, err := os.ReadFile(configPath)
+// LoadWithOrigins is like Load but also reports where each setting came from
+func LoadWithOrigins(repoRoot string) (*Config, Origins, error) {
+	config, origins, issues, err := Check(repoRoot)
 	if err != nil {
-		if os.IsNotExist(err) {
-			// Return default config if file doesn't exist
-			return DefaultConfig(), nil
+		return nil, nil, err
+	}
+	for _, issue := range issues {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", issue)
+	}
+	return config, origins, nil
+}
+
+// Issue is a problem with a setting in a config file
+type Issue struct {
+	Source  string // Config file, environment variable or "default"
+	Key     string
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Source, i.Key, i.Message)
+}
+
+// Check loads the configuration like LoadWithOrigins, returning unknown keys
+// as issues instead of printing warnings
+func Check(repoRoot string) (*Config, Origins, []Issue, error) {
+	config := DefaultConfig()
+	origins := make(Origins)
+	var issues []Issue
+
+	for _, path := range Paths(repoRoot) {
+		unknown, err := config.decodeFile(path, origins)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for _, key := range unknown {
+			issues = append(issues, Issue{Source: DisplayPath(repoRoot, path), Key: key, Message: unknownKeyMessage(key)})
 		}
-		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var config Config
-	if _, err := toml.Decode(string(data), &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	for _, key := range Keys() {
+		name := EnvName(key)
+		if raw, ok := os.LookupEnv(name); ok {
+			if err := config.setFromString(key, raw); err != nil {
+				return nil, nil, nil, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			origins[key] = "$" + name
+			config.sources = append(config.sources, "$"+name)
+		}
 	}
 
 	// Fill in defaults for empty values
 	config.fillDefaults()
 
-	return &config, nil
+	return config, origins, issues, nil
+}
+
+// Paths returns the config files merged by Load, lowest precedence first
+func Paths(repoRoot string) []string {
+	var paths []string
+	if path := UserPath(); path != "" {
+		paths = append(paths, path)
+	}
+	return append(paths, RepoPath(repoRoot), LocalPath(repoRoot))
+}
+
+// RepoPath returns the repository config file
+func RepoPath(repoRoot string) string {
+	return filepath.Join(repoRoot, worktreeDir, configFile)
+}
+
+// UserPath returns the per-user config file, honoring $XDG_CONFIG_HOME
+func UserPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "wtree", configFile)
+}
+
+// DisplayPath shortens a config file path relative to the repository or home
+func DisplayPath(repoRoot, path string) string {
+	if rel, err := filepath.Rel(repoRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.Join("~", rel)
+		}
+	}
+	return path
+}
+
+// LocalPath returns the uncommitted per-checkout config file
+func LocalPath(repoRoot string) string {
+	return filepath.Join(repoRoot, worktreeDir, localConfigFile)
+}
+
+// WriteIgnoreFile creates .wtree/.gitignore so that sessions, the trash and
+// config.local.toml stay out of git. An existing file is left alone.
+func WriteIgnoreFile(repoRoot string) error {
+	path := filepath.Join(repoRoot, worktreeDir, ignoreFile)
+	if _, err := os.Lstat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create .wtree directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(ignoreContent), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ignoreFile, err)
+	}
+	return nil
+}
+
+// decodeFile merges a config file into c, if it exists, and returns the keys
+// it doesn't know
+func (c *Config) decodeFile(path string, origins Origins) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	c.sources = append(c.sources, path)
+
+	// Older files are upgraded in memory; 'wtree migrate' rewrites them
+	text, _, err := migrateText(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	// Decoding replaces whole profiles, so they are merged separately
+	profiles := c.Profiles
+	c.Profiles = nil
+	md, err := toml.Decode(text, c)
+	if err != nil {
+		c.Profiles = profiles
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	decoded := c.Profiles
+	c.Profiles = profiles
+
+	for _, key := range md.Keys() {
+		switch {
+		case len(key) == 2 && key[0] == "profile":
+			c.addProfile(key[1])
+		case len(key) == 2:
+			origins[key.String()] = path
+		case len(key) == 4 && key[0] == "profile":
+			if c.mergeProfileKey(key[1], key[2]+"."+key[3], decoded[key[1]]) {
+				origins[key.String()] = path
+			}
+		}
+	}
+
+	// Report an unknown section once, not each key in it
+	var unknown []string
+	reported := make(map[string]bool)
+	for _, key := range md.Undecoded() {
+		if len(key) > 1 && reported[key[0]] {
+			continue
+		}
+		reported[key.String()] = true
+		unknown = append(unknown, key.String())
+	}
+	return unknown, nil
+}
+
+// addProfile declares a profile, which may not set anything
+func (c *Config) addProfile(name string) {
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]Profile)
+	}
+	if _, ok := c.Profiles[name]; !ok {
+		c.Profiles[name] = Profile{}
+	}
+}
+
+// mergeProfileKey copies one setting of a profile decoded from a config file
+// into the merged profile, and reports whether the setting is known
+func (c *Config) mergeProfileKey(name, key string, decoded Profile) bool {
+	from, ok := lookup(reflect.ValueOf(&decoded).Elem(), key)
+	if !ok {
+		return false
+	}
+
+	c.addProfile(name)
+	profile := c.Profiles[name]
+	to, _ := lookup(reflect.ValueOf(&profile).Elem(), key)
+	to.Set(from)
+	c.Profiles[name] = profile
+
+	if c.profileKeys == nil {
+		c.profileKeys = make(map[string][]string)
+	}
+	if !slices.Contains(c.profileKeys[name], key) {
+		c.profileKeys[name] = append(c.profileKeys[name], key)
+	}
+	return true
+}
+
+// ProfileNames returns the names of all profiles, sorted
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProfileKeys returns the settings a profile overrides
+func (c *Config) ProfileKeys(name string) []string {
+	return c.profileKeys[name]
+}
+
+// WithProfile returns a copy of the configuration with a profile's settings
+// applied on top of all other layers. An empty name returns c itself.
+func (c *Config) WithProfile(name string) (*Config, error) {
+	if name == "" {
+		return c, nil
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		if len(c.Profiles) == 0 {
+			return nil, fmt.Errorf("unknown profile '%s' (no [profile.<name>] sections configured)", name)
+		}
+		return nil, fmt.Errorf("unknown profile '%s' (available: %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+
+	result := *c
+	for _, key := range c.profileKeys[name] {
+		from, _ := lookup(reflect.ValueOf(&profile).Elem(), key)
+		to, _ := result.field(key)
+		to.Set(from)
+	}
+	result.fillDefaults()
+	return &result, nil
+}
+
+// unknownKeyMessage describes an unknown key, suggesting a known one that is
+// a likely typo of it
+func unknownKeyMessage(key string) string {
+	// Profile keys are checked against the settings they override
+	if parts := strings.SplitN(key, ".", 3); len(parts) == 3 && parts[0] == "profile" {
+		prefix := "profile." + parts[1] + "."
+		message := unknownKeyMessage(parts[2])
+		return strings.Replace(message, "did you mean ", "did you mean "+prefix, 1)
+	}
+
+	candidates := Keys()
+	if !strings.Contains(key, ".") {
+		candidates = sections()
+	}
+	best, bestDistance := "", 3
+	for _, known := range candidates {
+		if d := editDistance(key, known); d < bestDistance {
+			best, bestDistance = known, d
+		}
+	}
+	if best != "" {
+		return fmt.Sprintf("unknown key (did you mean %s?)", best)
+	}
+	return "unknown key"
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
 }
 
 // Save writes the configuration to config.toml
@@ -85,7 +506,14 @@ func Save(repoRoot string, config *Config) error {
 	return nil
 }
 
-// Exists checks if the config file exists
+// Sources returns the config files and environment variables (as "$NAME")
+// the configuration was loaded from, lowest precedence first. It is empty
+// when only defaults apply.
+func (c *Config) Sources() []string {
+	return c.sources
+}
+
+// Exists checks if the repository config file exists
 func Exists(repoRoot string) bool {
 	configPath := filepath.Join(repoRoot, worktreeDir, configFile)
 	_, err := os.Stat(configPath)
@@ -105,6 +533,12 @@ func (c *Config) fillDefaults() {
 	if c.Worktree.BaseBranch == "" {
 		c.Worktree.BaseBranch = defaults.Worktree.BaseBranch
 	}
+	if c.Merge.Strategy == "" {
+		c.Merge.Strategy = defaults.Merge.Strategy
+	}
+	if c.Terminal.Backend == "" {
+		c.Terminal.Backend = defaults.Terminal.Backend
+	}
 	if c.Terminal.Mode == "" {
 		c.Terminal.Mode = defaults.Terminal.Mode
 	}
@@ -1,7 +1,10 @@
 package config
 
+import "strconv"
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
+	keepIfLocked := true
 	return &Config{
 		Worktree: WorktreeConfig{
 			WorktreeBaseDir: "../worktree",
@@ -9,12 +12,19 @@ func DefaultConfig() *Config {
 			BaseBranch:      "main",
 		},
 		Setup: SetupConfig{
-			Copy:     []string{},
+			Copy:     []CopyEntry{},
 			Commands: []string{},
 		},
 		Terminal: TerminalConfig{
-			Mode: "tab",
-			Exec: "",
+			Backend: "auto",
+			Mode:    "tab",
+			Exec:    "",
+		},
+		Merge: MergeConfig{
+			Strategy: "merge",
+		},
+		Prune: PruneConfig{
+			KeepIfLocked: &keepIfLocked,
 		},
 	}
 }
@@ -24,29 +34,96 @@ func ConfigTemplate(baseBranch string) string {
 	if baseBranch == "" {
 		baseBranch = "main"
 	}
-	return `[worktree]
+	return `version = ` + strconv.Itoa(Version) + `
+
+[worktree]
 # Directory where worktrees are created (relative to repo root)
 worktree_base_dir = "../worktree"
 # Branch name prefix
 branch_prefix = "wt/"
 # Base branch for new worktrees
 base_branch = "` + baseBranch + `"
+# Use the worktree name (wtree new --name) instead of the ID in branch names
+# branch_from_name = true
 
 [setup]
-# Files/directories to copy to new worktrees (supports gitignored files)
+# Files/directories to copy to new worktrees (supports gitignored files).
+# Entries may be glob patterns ("**" matches any number of directories) or
+# tables choosing how to bring them in:
+#   mode = "copy" (default; copy-on-write clone where the filesystem supports it)
+#        | "symlink" | "hardlink" | "reflink" (clone or fail)
+# Symlinks to ignored entries are added to .git/info/exclude.
 copy = [
     # ".env",
+    # "**/.env*",
     # ".claude/",
+    # { path = "node_modules", mode = "symlink" },
+    # { path = "vendor", mode = "hardlink", exclude = ["*.log"] },
 ]
+# Patterns skipped when copying; a pattern without "/" matches names at any depth
+# exclude = [".DS_Store"]
 # Commands to run after worktree creation
 commands = [
     # "npm install",
 ]
 
 [terminal]
-# How to open Windows Terminal: "tab" | "pane" | "window"
+# Terminal to open worktrees in: "auto" | "tmux" | "zellij" | "wezterm" | "kitty" | "wt" | "none"
+# "auto" detects from $TMUX, $ZELLIJ, $WEZTERM_PANE, $KITTY_WINDOW_ID
+backend = "auto"
+# How to open the terminal: "tab" | "pane" | "window"
 mode = "pane"
 # Command to run after opening (optional)
 # exec = "claude"
+# Close terminal panes in a worktree when it is removed
+# close_on_remove = true
+
+[merge]
+# How 'wtree merge' integrates branches: "merge" | "squash" | "rebase" | "ff-only" | "no-ff"
+strategy = "merge"
+# Commands that must pass in the worktree before merging (skip with --no-verify)
+verify = [
+    # "go test ./...",
+]
+# Verify on a trial rebase onto the target in a temporary worktree.
+# The temporary worktree does not get [setup] copies or commands.
+# verify_rebase = true
+
+[prune]
+# Policies for 'wtree prune' in addition to removing merged worktrees.
+# Inactive time counts from the later of creation and the branch's last commit.
+# Worktrees with uncommitted changes are never removed by these policies.
+# Remove clean worktrees (nothing uncommitted or unmerged) inactive this long
+# remove_clean_after = "3d"
+# Remove any worktree inactive this long; branches with unmerged commits are kept
+# max_age = "14d"
+# Never remove worktrees locked with 'wtree lock' or 'git worktree lock'
+# keep_if_locked = true
+
+[trash]
+# 'wtree rm' and 'wtree prune' keep removed worktrees restorable with
+# 'wtree restore': the branch and uncommitted changes are always kept.
+# Also keep untracked files and the [setup] copy files (e.g. .env)
+# keep_untracked = true
+
+[hooks]
+# Commands run on worktree lifecycle events. Each command receives
+# WTREE_HOOK, WTREE_ID, WTREE_NAME, WTREE_BRANCH, WTREE_PATH, WTREE_BASE_BRANCH,
+# WTREE_PROFILE and WTREE_REPO_ROOT. A failing pre_* hook aborts the operation.
+# pre_create = []
+# post_create = ["docker compose up -d"]
+# pre_remove = ["docker compose down -v"]
+# post_remove = []
+# pre_merge = []
+# post_merge = []
+
+# Profiles override [worktree], [setup], [terminal] and [hooks] keys for
+# worktrees created with 'wtree new --profile <name>'. Every later command on
+# the worktree uses the profile too; hooks get it in WTREE_PROFILE.
+# [profile.backend]
+# worktree.base_branch = "develop"
+# setup.commands = ["go mod download"]
+# terminal.exec = "claude"
+# hooks.pre_remove = ["docker compose down -v"]
 `
 }
@@ -0,0 +1,340 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+var (
+	sectionPattern    = regexp.MustCompile(`^\s*\[\s*([A-Za-z0-9_.-]+)\s*\]\s*(#.*)?$`)
+	arrayTablePattern = regexp.MustCompile(`^\s*\[\[\s*([A-Za-z0-9_.-]+)\s*\]\]\s*(#.*)?$`)
+)
+
+// ParseValue converts a value given on the command line to a TOML value for
+// key, checking its type. Lists accept a TOML array or a single item.
+func ParseValue(key, raw string) (string, error) {
+	c := DefaultConfig()
+	if err := c.setFromString(key, raw); err != nil {
+		return "", err
+	}
+	value, _ := c.Value(key)
+	return value, nil
+}
+
+// SetInFile sets key to a TOML value in the config file at path, creating the
+// file if needed. Comments and the rest of the file are left untouched.
+func SetInFile(path, key, value string) error {
+	section, name, ok := strings.Cut(key, ".")
+	if !ok {
+		return fmt.Errorf("unknown key: %s", key)
+	}
+	doc, err := readDocument(path)
+	if err != nil {
+		return err
+	}
+
+	if len(doc.lines) == 0 {
+		if err := doc.set("", "version", strconv.Itoa(Version)); err != nil {
+			return err
+		}
+	}
+	if err := doc.set(section, name, value); err != nil {
+		return fmt.Errorf("cannot set %s in %s: %w", key, path, err)
+	}
+	return doc.write(path)
+}
+
+// UnsetInFile removes key from the config file at path and reports whether
+// it was set there
+func UnsetInFile(path, key string) (bool, error) {
+	section, name, ok := strings.Cut(key, ".")
+	if !ok {
+		return false, fmt.Errorf("unknown key: %s", key)
+	}
+	doc, err := readDocument(path)
+	if err != nil {
+		return false, err
+	}
+
+	removed, err := doc.unset(section, name)
+	if err != nil {
+		return false, fmt.Errorf("cannot unset %s in %s: %w", key, path, err)
+	}
+	if !removed {
+		return false, nil
+	}
+	return true, doc.write(path)
+}
+
+// document is a config file edited line by line, so that comments and layout
+// survive changes
+type document struct {
+	lines []string
+}
+
+// readDocument reads a config file; a missing file is an empty document
+func readDocument(path string) (*document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &document{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return parseDocument(string(data)), nil
+}
+
+// parseDocument splits config file contents into lines
+func parseDocument(text string) *document {
+	if text == "" {
+		return &document{}
+	}
+	return &document{lines: strings.Split(strings.TrimSuffix(text, "\n"), "\n")}
+}
+
+func (d *document) String() string {
+	return strings.Join(d.lines, "\n") + "\n"
+}
+
+// set sets name in section (the top level if empty) to a TOML value, replacing
+// an existing value or adding the key to the section. A section written as
+// dotted keys at the top level (terminal.mode = ...) gets another dotted key.
+func (d *document) set(section, name, value string) error {
+	if err := checkForm(d.lines, section, name); err != nil {
+		return err
+	}
+
+	lines := d.lines
+	if start, end, key, ok := findKey(lines, section, name); ok {
+		d.lines = splice(lines, start, end, key+" = "+value)
+		return nil
+	}
+
+	entry := name + " = " + value
+	if section == "" {
+		// Top-level keys must come before the first section
+		if len(lines) > 0 && strings.TrimSpace(lines[0]) != "" {
+			d.lines = splice(lines, 0, 0, entry, "")
+		} else {
+			d.lines = splice(lines, 0, 0, entry)
+		}
+	} else if at, ok := dottedInsertionPoint(lines, section); ok {
+		d.lines = splice(lines, at, at, section+"."+entry)
+	} else if at, ok := insertionPoint(lines, section, name); ok {
+		d.lines = splice(lines, at, at, entry)
+	} else {
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+			lines = append(lines, "")
+		}
+		d.lines = append(lines, "["+section+"]", entry)
+	}
+	return nil
+}
+
+// unset removes name from section and reports whether it was there
+func (d *document) unset(section, name string) (bool, error) {
+	if err := checkForm(d.lines, section, name); err != nil {
+		return false, err
+	}
+	start, end, _, ok := findKey(d.lines, section, name)
+	if !ok {
+		return false, nil
+	}
+	d.lines = splice(d.lines, start, end)
+	return true, nil
+}
+
+// write writes the document to path after checking that it still parses
+func (d *document) write(path string) error {
+	data := d.String()
+	var config Config
+	if _, err := toml.Decode(data, &config); err != nil {
+		return fmt.Errorf("refusing to write %s, the result would not parse: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// tableHeader returns the table a header line opens. Arrays of tables are
+// returned in their brackets, so that they never match a section name.
+func tableHeader(line string) (string, bool) {
+	if m := sectionPattern.FindStringSubmatch(line); m != nil {
+		return m[1], true
+	}
+	if m := arrayTablePattern.FindStringSubmatch(line); m != nil {
+		return "[[" + m[1] + "]]", true
+	}
+	return "", false
+}
+
+// keyPattern matches a line assigning the dotted key made of parts, bare or
+// quoted. The first group is the key as written, including indentation.
+func keyPattern(parts ...string) *regexp.Regexp {
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		quoted[i] = keyPart(part)
+	}
+	return regexp.MustCompile(`^(\s*` + strings.Join(quoted, `\s*\.\s*`) + `)\s*=(.*)$`)
+}
+
+// keyPart returns a pattern for one part of a key, bare or quoted
+func keyPart(part string) string {
+	return `(?:` + regexp.QuoteMeta(part) + `|"` + regexp.QuoteMeta(part) + `")`
+}
+
+// checkForm reports a key written in a form that line editing can't change:
+// an array of tables ([[setup.copy]]) or an inline table (setup = { ... })
+func checkForm(lines []string, section, name string) error {
+	if section == "" {
+		return nil
+	}
+	inlinePattern := keyPattern(section)
+	current := ""
+	for _, line := range lines {
+		if header, ok := tableHeader(line); ok {
+			if header == "[["+section+"."+name+"]]" {
+				return fmt.Errorf("it is written as [[%s.%s]] tables, edit the file instead", section, name)
+			}
+			current = header
+			continue
+		}
+		if m := inlinePattern.FindStringSubmatch(line); current == "" && m != nil && strings.HasPrefix(strings.TrimSpace(m[2]), "{") {
+			return fmt.Errorf("[%s] is written as an inline table, edit the file instead", section)
+		}
+	}
+	return nil
+}
+
+// findKey returns the lines [start, end) holding key in section, including
+// the continuation lines of a multi-line array, and the key as written. Keys
+// of a section are also found as dotted keys at the top level.
+func findKey(lines []string, section, name string) (int, int, string, bool) {
+	inSection := keyPattern(name)
+	dotted := keyPattern(section, name)
+	current := ""
+	for i, line := range lines {
+		if header, ok := tableHeader(line); ok {
+			current = header
+			continue
+		}
+		var m []string
+		switch {
+		case current == section:
+			m = inSection.FindStringSubmatch(line)
+		case current == "" && section != "":
+			m = dotted.FindStringSubmatch(line)
+		}
+		if m == nil {
+			continue
+		}
+
+		return i, valueEnd(lines, i, bracketDepth(m[2])), strings.TrimRight(m[1], " \t"), true
+	}
+	return 0, 0, "", false
+}
+
+// dottedInsertionPoint returns where to add a key to a section written as
+// dotted keys at the top level: after the last of them
+func dottedInsertionPoint(lines []string, section string) (int, bool) {
+	prefix := regexp.MustCompile(`^\s*` + keyPart(section) + `\s*\.`)
+	at, found := 0, false
+	for i := 0; i < len(lines); i++ {
+		if _, ok := tableHeader(lines[i]); ok {
+			break
+		}
+		if prefix.MatchString(lines[i]) {
+			at, found = valueEnd(lines, i, bracketDepth(lines[i])), true
+			i = at - 1
+		}
+	}
+	return at, found
+}
+
+// valueEnd returns the line after a value starting on line i, following an
+// array until its brackets balance; depth is the nesting left open on line i
+func valueEnd(lines []string, i, depth int) int {
+	end := i + 1
+	for depth > 0 && end < len(lines) {
+		depth += bracketDepth(lines[end])
+		end++
+	}
+	return end
+}
+
+// insertionPoint returns where to add key to an existing section: after a
+// commented-out example of the key, or after the section's last setting
+func insertionPoint(lines []string, section, name string) (int, bool) {
+	examplePattern := regexp.MustCompile(`^\s*#\s*` + regexp.QuoteMeta(name) + `\s*=`)
+	current := ""
+	at, found := 0, false
+	for i, line := range lines {
+		if header, ok := tableHeader(line); ok {
+			if found && current == section {
+				break
+			}
+			current = header
+			if current == section {
+				at, found = i+1, true
+			}
+			continue
+		}
+		if current != section {
+			continue
+		}
+		if examplePattern.MatchString(line) {
+			return i + 1, true
+		}
+		if strings.TrimSpace(line) != "" {
+			at = i + 1
+		}
+	}
+	return at, found
+}
+
+// bracketDepth returns the change in '[' nesting over a line, ignoring
+// brackets in strings and comments
+func bracketDepth(line string) int {
+	depth := 0
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if r == '\\' && quote == '"' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return depth
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		}
+	}
+	return depth
+}
+
+// splice replaces lines[start:end] with the given lines
+func splice(lines []string, start, end int, with ...string) []string {
+	result := append([]string{}, lines[:start]...)
+	result = append(result, with...)
+	return append(result, lines[end:]...)
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Keys returns the dotted names of all settings (e.g. "terminal.mode"), in
+// the order they are declared in Config
+func Keys() []string {
+	var keys []string
+	sections := reflect.TypeOf(Config{})
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Field(i)
+		if section.Type.Kind() != reflect.Struct {
+			continue
+		}
+		for j := 0; j < section.Type.NumField(); j++ {
+			keys = append(keys, section.Tag.Get("toml")+"."+section.Type.Field(j).Tag.Get("toml"))
+		}
+	}
+	return keys
+}
+
+// sections returns the names of all config sections
+func sections() []string {
+	var names []string
+	for _, key := range Keys() {
+		section, _, _ := strings.Cut(key, ".")
+		if len(names) == 0 || names[len(names)-1] != section {
+			names = append(names, section)
+		}
+	}
+	return names
+}
+
+// CheckKey returns an error for an unknown key, suggesting a likely intended one
+func CheckKey(key string) error {
+	for _, known := range Keys() {
+		if known == key {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: %s", key, unknownKeyMessage(key))
+}
+
+// EnvName returns the environment variable that overrides a setting,
+// e.g. WTREE_TERMINAL_MODE for terminal.mode
+func EnvName(key string) string {
+	return "WTREE_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// field returns the struct field holding a setting
+func (c *Config) field(key string) (reflect.Value, bool) {
+	return lookup(reflect.ValueOf(c).Elem(), key)
+}
+
+// lookup returns the field holding a setting in a struct of sections, such
+// as Config or Profile
+func lookup(root reflect.Value, key string) (reflect.Value, bool) {
+	sectionName, name, ok := strings.Cut(key, ".")
+	if !ok {
+		return reflect.Value{}, false
+	}
+	section, ok := fieldByTag(root, sectionName)
+	if !ok || section.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	return fieldByTag(section, name)
+}
+
+// fieldByTag returns the field of a struct with the given toml tag
+func fieldByTag(v reflect.Value, tag string) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Tag.Get("toml") == tag {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// Value returns a setting formatted as a TOML value
+func (c *Config) Value(key string) (string, bool) {
+	v, ok := c.field(key)
+	if !ok {
+		return "", false
+	}
+	return formatValue(v), true
+}
+
+// formatValue formats a string, bool, *bool or list field as TOML
+func formatValue(v reflect.Value) string {
+	if entry, ok := v.Interface().(CopyEntry); ok {
+		return entry.tomlValue()
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return ""
+		}
+		return formatValue(v.Elem())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = formatValue(v.Index(i))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return strconv.Quote(v.String())
+	}
+}
+
+// setFromString sets a setting from a plain string such as an environment
+// variable. Lists accept a TOML array ("[\"a\", \"b\"]") or a single item,
+// which may be an inline table.
+func (c *Config) setFromString(key, raw string) error {
+	v, ok := c.field(key)
+	if !ok {
+		return fmt.Errorf("unknown key: %s", key)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool, reflect.Pointer:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false, got %q", key, raw)
+		}
+		if v.Kind() == reflect.Pointer {
+			v.Set(reflect.ValueOf(&b))
+		} else {
+			v.SetBool(b)
+		}
+	case reflect.Slice:
+		switch trimmed := strings.TrimSpace(raw); {
+		case strings.HasPrefix(trimmed, "{"):
+			raw = "[" + trimmed + "]"
+		case !strings.HasPrefix(trimmed, "["):
+			raw = "[" + strconv.Quote(raw) + "]"
+		}
+		// Decode into a document with a single key of the field's type
+		doc := reflect.New(reflect.StructOf([]reflect.StructField{
+			{Name: "V", Type: v.Type(), Tag: `toml:"v"`},
+		}))
+		if _, err := toml.Decode("v = "+raw, doc.Interface()); err != nil {
+			return fmt.Errorf("%s must be a list: %w", key, err)
+		}
+		v.Set(doc.Elem().Field(0))
+	}
+	return nil
+}
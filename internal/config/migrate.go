@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Version is the current format of config files
+const Version = 1
+
+// migration upgrades a config file from the previous version. Migrations edit
+// the file as a document, so comments survive.
+type migration struct {
+	version     int // Version the migration upgrades to
+	description string
+	migrate     func(doc *document) error
+}
+
+// migrations are applied in order to files older than their version
+var migrations = []migration{
+	{
+		version:     1,
+		description: "record the config version",
+		migrate:     func(doc *document) error { return nil },
+	},
+}
+
+// migrateText upgrades config file contents to the current version and
+// returns them with the descriptions of the migrations applied
+func migrateText(text string) (string, []string, error) {
+	var header struct {
+		Version int `toml:"version"`
+	}
+	if _, err := toml.Decode(text, &header); err != nil {
+		return "", nil, err
+	}
+	if header.Version > Version {
+		return "", nil, fmt.Errorf("config version %d is newer than this wtree supports (%d); upgrade wtree", header.Version, Version)
+	}
+	if header.Version == Version {
+		return text, nil, nil
+	}
+
+	doc := parseDocument(text)
+	var applied []string
+	for _, m := range migrations {
+		if m.version <= header.Version {
+			continue
+		}
+		if err := m.migrate(doc); err != nil {
+			return "", nil, fmt.Errorf("failed to migrate to version %d: %w", m.version, err)
+		}
+		if err := doc.set("", "version", strconv.Itoa(m.version)); err != nil {
+			return "", nil, err
+		}
+		applied = append(applied, m.description)
+	}
+	return doc.String(), applied, nil
+}
+
+// MigrateFile upgrades the config file at path to the current version. It
+// returns the file before and after the upgrade (equal when it is up to date
+// or missing) and the migrations applied. With dryRun nothing is written.
+func MigrateFile(path string, dryRun bool) (before, after string, applied []string, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", nil, nil
+		}
+		return "", "", nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	before = string(data)
+	after, applied, err = migrateText(before)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(applied) > 0 && !dryRun {
+		if err := parseDocument(after).write(path); err != nil {
+			return "", "", nil, err
+		}
+	}
+	return before, after, applied, nil
+}
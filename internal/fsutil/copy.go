@@ -0,0 +1,173 @@
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Mode selects how a file or directory is brought into a worktree
+type Mode string
+
+const (
+	ModeCopy     Mode = "copy"     // Clone (copy-on-write) where supported, otherwise copy
+	ModeSymlink  Mode = "symlink"  // Symlink to the source
+	ModeHardlink Mode = "hardlink" // Hard-link files; directories are recreated
+	ModeReflink  Mode = "reflink"  // Clone, failing where the filesystem can't
+)
+
+// ParseMode validates a mode name; empty means ModeCopy
+func ParseMode(name string) (Mode, error) {
+	switch m := Mode(name); m {
+	case "":
+		return ModeCopy, nil
+	case ModeCopy, ModeSymlink, ModeHardlink, ModeReflink:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown copy mode '%s' (use copy, symlink, hardlink or reflink)", name)
+}
+
+var errReflinkUnsupported = errors.New("reflinks are not supported on this platform")
+
+// Options control Copy
+type Options struct {
+	Mode Mode
+	// Exclude reports whether to skip a path below the source directory,
+	// given relative to it with forward slashes
+	Exclude func(rel string) bool
+}
+
+// CopyPath copies a file, symlink or directory, preserving permissions and symlinks
+func CopyPath(src, dst string) error {
+	return Copy(src, dst, Options{Mode: ModeCopy})
+}
+
+// Copy brings src to dst as selected by opts.Mode. Permissions and symlinks
+// are preserved; existing files at dst are replaced.
+func Copy(src, dst string, opts Options) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	if opts.Mode == ModeSymlink {
+		abs, err := filepath.Abs(src)
+		if err != nil {
+			return err
+		}
+		if err := removeExisting(dst); err != nil {
+			return err
+		}
+		return os.Symlink(abs, dst)
+	}
+	return copyTree(src, dst, "", info, opts)
+}
+
+// copyTree copies src, which is at rel below the source root
+func copyTree(src, dst, rel string, info os.FileInfo, opts Options) error {
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		return copySymlink(src, dst)
+	case info.IsDir():
+		return copyDir(src, dst, rel, info, opts)
+	case info.Mode().IsRegular():
+		return copyFile(src, dst, info.Mode().Perm(), opts.Mode)
+	default:
+		// Sockets, pipes and devices are skipped
+		return nil
+	}
+}
+
+func copyDir(src, dst, rel string, info os.FileInfo, opts Options) error {
+	// Writable while copying; the source permissions are applied at the end
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		childRel := path.Join(rel, entry.Name())
+		if opts.Exclude != nil && opts.Exclude(childRel) {
+			continue
+		}
+		childInfo, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if err := copyTree(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name()), childRel, childInfo, opts); err != nil {
+			return err
+		}
+	}
+
+	return os.Chmod(dst, info.Mode().Perm())
+}
+
+func copyFile(src, dst string, perm os.FileMode, mode Mode) error {
+	if err := removeExisting(dst); err != nil {
+		return err
+	}
+	if mode == ModeHardlink {
+		return os.Link(src, dst)
+	}
+
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	dstFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	defer dstFile.Close()
+
+	if err := cloneFile(dstFile, srcFile); err != nil {
+		if mode == ModeReflink {
+			return fmt.Errorf("cannot reflink %s: %w", src, err)
+		}
+		if _, err := io.Copy(dstFile, srcFile); err != nil {
+			return err
+		}
+	}
+
+	// The umask may have narrowed perm
+	return dstFile.Chmod(perm)
+}
+
+func copySymlink(src, dst string) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	if err := removeExisting(dst); err != nil {
+		return err
+	}
+	return os.Symlink(target, dst)
+}
+
+// removeExisting removes a file or symlink at path so it can be replaced.
+// Directories are left alone and reported as an error.
+func removeExisting(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s already exists as a directory", path)
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,138 @@
+package fsutil
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// HasMeta reports whether pattern contains glob characters
+func HasMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// Match reports whether a slash-separated path matches pattern. "**" matches
+// any number of directories; other segments use path.Match syntax.
+func Match(pattern, name string) bool {
+	return matchSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], segments[0]); err != nil || !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
+
+// Matcher returns a function reporting whether a slash-separated path matches
+// any of patterns. As in .gitignore, a pattern without a slash matches a file
+// or directory name at any depth; others match the whole path.
+func Matcher(patterns []string) func(name string) bool {
+	return func(name string) bool {
+		base := path.Base(name)
+		for _, pattern := range patterns {
+			pattern = strings.TrimSuffix(pattern, "/")
+			if pattern == "" {
+				continue
+			}
+			if !strings.Contains(pattern, "/") {
+				if ok, _ := path.Match(pattern, base); ok {
+					return true
+				}
+			} else if Match(pattern, name) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Glob returns the paths below root, relative to it with forward slashes, that
+// match pattern. A pattern without glob characters is returned as is if it
+// exists. Directories that match are not searched further, and neither are
+// paths for which exclude returns true or other checkouts (directories with a
+// .git entry, such as worktrees and submodules).
+func Glob(root, pattern string, exclude func(name string) bool) ([]string, error) {
+	pattern = strings.Trim(filepath.ToSlash(filepath.Clean(pattern)), "/")
+	if !HasMeta(pattern) {
+		if _, err := os.Lstat(filepath.Join(root, filepath.FromSlash(pattern))); err != nil {
+			return nil, err
+		}
+		return []string{pattern}, nil
+	}
+
+	// Start the walk at the literal directories leading the pattern
+	segments := strings.Split(pattern, "/")
+	start := 0
+	for start < len(segments) && !HasMeta(segments[start]) {
+		start++
+	}
+	base := path.Join(segments[:start]...)
+	recursive := false
+	for _, segment := range segments {
+		if segment == "**" {
+			recursive = true
+		}
+	}
+
+	var matches []string
+	err := filepath.WalkDir(filepath.Join(root, filepath.FromSlash(base)), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." || rel == base {
+			return nil
+		}
+
+		if d.Name() == ".git" || (exclude != nil && exclude(rel)) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if _, err := os.Lstat(filepath.Join(p, ".git")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+		if Match(pattern, rel) {
+			matches = append(matches, rel)
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() && !recursive && strings.Count(rel, "/")+1 >= len(segments) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
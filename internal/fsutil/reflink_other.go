@@ -0,0 +1,9 @@
+//go:build !linux
+
+package fsutil
+
+import "os"
+
+func cloneFile(dst, src *os.File) error {
+	return errReflinkUnsupported
+}
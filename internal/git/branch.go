@@ -64,3 +64,78 @@ func BranchExists(branch string) bool {
 	cmd := exec.Command("git", "rev-parse", "--verify", branch)
 	return cmd.Run() == nil
 }
+
+// LocalBranchExists checks if a local branch (refs/heads/<branch>) exists
+func LocalBranchExists(branch string) bool {
+	cmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
+	return cmd.Run() == nil
+}
+
+// RemoteBranchExists checks if 'git worktree add' can create a local branch
+// from a remote branch of the same name: exactly one remote has it, or
+// several do and one of them is checkout.defaultRemote
+func RemoteBranchExists(branch string) bool {
+	output, err := exec.Command("git", "remote").Output()
+	if err != nil {
+		return false
+	}
+
+	var remotes []string
+	for _, remote := range strings.Fields(string(output)) {
+		if CommitExists("refs/remotes/" + remote + "/" + branch) {
+			remotes = append(remotes, remote)
+		}
+	}
+	if len(remotes) == 1 {
+		return true
+	}
+
+	output, err = exec.Command("git", "config", "--get", "checkout.defaultRemote").Output()
+	if err != nil {
+		return false
+	}
+	defaultRemote := strings.TrimSpace(string(output))
+	for _, remote := range remotes {
+		if remote == defaultRemote {
+			return true
+		}
+	}
+	return false
+}
+
+// CommitExists checks if ref resolves to a commit (branch, tag, SHA, remote branch...)
+func CommitExists(ref string) bool {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	return cmd.Run() == nil
+}
+
+// RevParse resolves ref to a full commit hash
+func RevParse(ref string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("unknown revision: %s", ref)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// UpdateRef points ref (a full ref name) at commit, creating it if needed
+func UpdateRef(ref, commit, message string) error {
+	cmd := exec.Command("git", "update-ref", "-m", message, ref, commit)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to update %s: %s", ref, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// DeleteRef deletes ref (a full ref name) if it exists
+func DeleteRef(ref string) error {
+	if !CommitExists(ref) {
+		return nil
+	}
+	cmd := exec.Command("git", "update-ref", "-d", ref)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete %s: %s", ref, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
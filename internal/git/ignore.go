@@ -0,0 +1,52 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// IsIgnored reports whether path, relative to the checkout in dir, is ignored
+// by git
+func IsIgnored(dir, path string) bool {
+	cmd := exec.Command("git", "-C", dir, "check-ignore", "-q", "--", path)
+	return cmd.Run() == nil
+}
+
+// AddExclude appends pattern to the info/exclude file of the repository dir
+// belongs to, unless it is already listed. The file is shared by all
+// worktrees of the repository.
+func AddExclude(dir, pattern string) error {
+	cmd := exec.Command("git", "-C", dir, "rev-parse", "--git-path", "info/exclude")
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to locate info/exclude: %w", err)
+	}
+	path := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	content := string(data)
+	if slices.Contains(strings.Split(content, "\n"), pattern) {
+		return nil
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content+pattern+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
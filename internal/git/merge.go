@@ -1,41 +1,265 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 	"os/exec"
+	"slices"
 	"strings"
 )
 
-// Merge merges the given branch into the current branch
-func Merge(branch string) error {
-	cmd := exec.Command("git", "merge", branch)
+// ErrMergeConflict is returned when a merge or rebase stops on conflicts
+var ErrMergeConflict = errors.New("merge conflict detected")
+
+// MergeStrategy selects how a worktree branch is integrated
+type MergeStrategy string
+
+const (
+	StrategyMerge  MergeStrategy = "merge"   // git merge (fast-forward when possible)
+	StrategySquash MergeStrategy = "squash"  // squash all commits into one
+	StrategyRebase MergeStrategy = "rebase"  // rebase the branch, then fast-forward
+	StrategyFFOnly MergeStrategy = "ff-only" // fast-forward or fail
+	StrategyNoFF   MergeStrategy = "no-ff"   // always create a merge commit
+)
+
+// ParseMergeStrategy validates a strategy name
+func ParseMergeStrategy(name string) (MergeStrategy, error) {
+	switch s := MergeStrategy(name); s {
+	case StrategyMerge, StrategySquash, StrategyRebase, StrategyFFOnly, StrategyNoFF:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown merge strategy '%s' (use merge, squash, rebase, ff-only or no-ff)", name)
+}
+
+// Merge merges the given branch into the branch checked out in dir
+func Merge(dir, branch string) error {
+	return runMerge(dir, "merge", "--no-edit", branch)
+}
+
+// MergeNoFF merges the given branch into dir with a merge commit.
+// An empty message uses git's default.
+func MergeNoFF(dir, branch, message string) error {
+	args := []string{"merge", "--no-ff", "--no-edit"}
+	if message != "" {
+		args = append(args, "-m", message)
+	}
+	return runMerge(dir, append(args, branch)...)
+}
+
+// MergeFFOnly fast-forwards the branch checked out in dir to the given branch
+func MergeFFOnly(dir, branch string) error {
+	cmd := exec.Command("git", "-C", dir, "merge", "--ff-only", branch)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		outputStr := strings.TrimSpace(string(output))
+		if strings.Contains(outputStr, "Not possible to fast-forward") {
+			return fmt.Errorf("cannot fast-forward: %s has diverged, rebase it first", branch)
+		}
+		return fmt.Errorf("failed to merge: %s", outputStr)
+	}
+	return nil
+}
+
+// MergeSquash stages the changes of the given branch in dir without committing
+func MergeSquash(dir, branch string) error {
+	return runMerge(dir, "merge", "--squash", branch)
+}
+
+// runMerge runs a git merge command in dir, detecting conflicts
+func runMerge(dir string, args ...string) error {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		outputStr := strings.TrimSpace(string(output))
 		if strings.Contains(outputStr, "CONFLICT") || strings.Contains(outputStr, "Automatic merge failed") {
-			return fmt.Errorf("merge conflict detected")
+			return ErrMergeConflict
 		}
 		return fmt.Errorf("failed to merge: %s", outputStr)
 	}
 	return nil
 }
 
+// Commit commits the staged changes in dir with the given message
+func Commit(dir, message string) error {
+	cmd := exec.Command("git", "-C", dir, "commit", "-F", "-")
+	cmd.Stdin = strings.NewReader(message)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to commit: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Rebase rebases the branch checked out in worktreePath onto the given ref.
+// On conflict the rebase is aborted, leaving the worktree unchanged.
+func Rebase(worktreePath, onto string) error {
+	cmd := exec.Command("git", "-C", worktreePath, "rebase", onto)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		outputStr := strings.TrimSpace(string(output))
+		exec.Command("git", "-C", worktreePath, "rebase", "--abort").Run()
+		if strings.Contains(outputStr, "CONFLICT") || strings.Contains(outputStr, "could not apply") {
+			return ErrMergeConflict
+		}
+		return fmt.Errorf("failed to rebase: %s", outputStr)
+	}
+	return nil
+}
+
+// IsAncestor reports whether ancestor is reachable from ref, i.e. ref can be
+// fast-forwarded from ancestor
+func IsAncestor(ancestor, ref string) bool {
+	cmd := exec.Command("git", "merge-base", "--is-ancestor", ancestor, ref)
+	return cmd.Run() == nil
+}
+
+// FastForwardBranch moves a branch that is not checked out anywhere to ref.
+// It fails if the branch is not an ancestor of ref or moved concurrently.
+func FastForwardBranch(branch, ref string) error {
+	oldRev, err := RevParse("refs/heads/" + branch)
+	if err != nil {
+		return err
+	}
+	newRev, err := RevParse(ref)
+	if err != nil {
+		return err
+	}
+	if !IsAncestor(oldRev, newRev) {
+		return fmt.Errorf("cannot fast-forward %s to %s", branch, ref)
+	}
+
+	cmd := exec.Command("git", "update-ref", "-m", "wtree: fast-forward to "+ref, "refs/heads/"+branch, newRev, oldRev)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to update %s: %s", branch, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// MergeTreeConflicts performs an in-memory merge of two refs with
+// 'git merge-tree --write-tree' (git 2.38+) and returns the conflicting paths.
+// No checkout, index or ref is touched.
+func MergeTreeConflicts(ref1, ref2 string) ([]string, error) {
+	_, paths, err := mergeTree(ref1, ref2)
+	return paths, err
+}
+
+// ContainsResolved reports whether target already holds every change of
+// branch, counting conflicting files as resolved: merging branch into target
+// would change nothing but the files that conflict. This recognizes a squash
+// merge whose conflicts were resolved by hand, which IsMerged can't.
+func ContainsResolved(target, branch string) bool {
+	tree, conflicts, err := mergeTree(target, branch)
+	if err != nil {
+		return false
+	}
+
+	cmd := exec.Command("git", "diff", "--name-only", "--no-renames", target, tree)
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	for _, path := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if path != "" && !slices.Contains(conflicts, path) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeTree runs 'git merge-tree --write-tree' and returns the resulting tree,
+// with conflict markers in conflicting files, and the conflicting paths
+func mergeTree(ref1, ref2 string) (string, []string, error) {
+	cmd := exec.Command("git", "merge-tree", "--write-tree", "--name-only", "--no-messages", ref1, ref2)
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return "", nil, fmt.Errorf("failed to run merge-tree (requires git 2.38+): %w", err)
+		}
+	}
+
+	// The first line is the resulting tree; conflicted paths follow
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	var paths []string
+	for _, line := range lines[1:] {
+		if line = strings.TrimSpace(line); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return lines[0], paths, nil
+}
+
+// CommitSubjects returns the subjects of commits in branch but not in baseBranch, oldest first
+func CommitSubjects(baseBranch, branch string) ([]string, error) {
+	cmd := exec.Command("git", "log", "--reverse", "--format=%s", baseBranch+".."+branch)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+
+	var subjects []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			subjects = append(subjects, line)
+		}
+	}
+	return subjects, nil
+}
+
 // MergeCommitCount returns the number of commits that would be merged
 func MergeCommitCount(baseBranch, branch string) (int, error) {
 	return GetAheadCount(baseBranch, branch)
 }
 
-// HasMergeConflict checks if there's currently a merge conflict
-func HasMergeConflict() bool {
-	cmd := exec.Command("git", "ls-files", "--unmerged")
+// HasMergeConflict checks if there are unmerged files in dir
+func HasMergeConflict(dir string) bool {
+	files, err := ConflictedFiles(dir)
+	return err == nil && len(files) > 0
+}
+
+// ConflictedFiles returns the paths with unmerged index entries in dir
+func ConflictedFiles(dir string) ([]string, error) {
+	cmd := exec.Command("git", "-C", dir, "ls-files", "--unmerged")
 	output, err := cmd.Output()
 	if err != nil {
-		return false
+		return nil, fmt.Errorf("failed to list conflicted files: %w", err)
+	}
+
+	// Each line is "<mode> <object> <stage>\t<path>", one per conflict stage
+	var files []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		_, path, ok := strings.Cut(line, "\t")
+		if !ok || seen[path] {
+			continue
+		}
+		seen[path] = true
+		files = append(files, path)
 	}
-	return len(strings.TrimSpace(string(output))) > 0
+	return files, nil
 }
 
-// AbortMerge aborts an ongoing merge
-func AbortMerge() error {
-	cmd := exec.Command("git", "merge", "--abort")
-	return cmd.Run()
+// IsMergeInProgress checks if dir has an unfinished (non-squash) merge
+func IsMergeInProgress(dir string) bool {
+	cmd := exec.Command("git", "-C", dir, "rev-parse", "-q", "--verify", "MERGE_HEAD")
+	return cmd.Run() == nil
+}
+
+// ContinueMerge commits a merge in dir whose conflicts have been resolved
+func ContinueMerge(dir string) error {
+	cmd := exec.Command("git", "-C", dir, "commit", "--no-edit")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to commit merge: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// AbortMerge aborts an ongoing merge in dir. Squash merges leave no
+// MERGE_HEAD, so they are rolled back with 'reset --merge'.
+func AbortMerge(dir string) error {
+	args := []string{"-C", dir, "reset", "--merge"}
+	if IsMergeInProgress(dir) {
+		args = []string{"-C", dir, "merge", "--abort"}
+	}
+	cmd := exec.Command("git", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to abort merge: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
 }
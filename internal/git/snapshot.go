@@ -0,0 +1,260 @@
+package git
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// statusWorkers bounds how many per-worktree git processes run at once
+const statusWorkers = 8
+
+// Snapshot holds repository-wide state gathered with a few git calls, so the
+// status of many worktrees can be computed without a process per question
+type Snapshot struct {
+	baseBranch string
+	worktrees  []WorktreeInfo
+	heads      map[string]string      // Branch name -> commit
+	commitTime map[string]time.Time   // Branch name -> committer date of its head
+	baseTree   string                 // Tree of the base branch; empty if it doesn't exist
+	merged     map[string]bool        // Branches whose tip is reachable from base
+	counts     map[string]aheadBehind // nil if git lacks %(ahead-behind)
+}
+
+// aheadBehind holds a branch's commit counts relative to the base branch
+type aheadBehind struct {
+	ahead, behind int
+}
+
+// StatusTarget identifies a worktree whose status should be computed
+type StatusTarget struct {
+	Path   string
+	Branch string
+	Locked bool // Locked by the caller's own bookkeeping, in addition to git
+}
+
+// StatusResult is the status of one StatusTarget
+type StatusResult struct {
+	Info *StatusInfo
+	Err  error
+}
+
+// NewSnapshot collects worktrees, branch heads and merge state for baseBranch.
+// A missing base branch is not an error; nothing is reported as merged then.
+func NewSnapshot(baseBranch string) (*Snapshot, error) {
+	worktrees, err := ListWorktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	heads, commitTime, err := branchHeads()
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &Snapshot{
+		baseBranch: baseBranch,
+		worktrees:  worktrees,
+		heads:      heads,
+		commitTime: commitTime,
+		baseTree:   treeOf(baseBranch),
+		merged:     mergedBranches(baseBranch),
+		counts:     aheadBehindCounts(baseBranch),
+	}
+	return snap, nil
+}
+
+// WorktreeExists reports whether a worktree is registered at path
+func (s *Snapshot) WorktreeExists(path string) bool {
+	return s.worktree(path) != nil
+}
+
+// worktree returns the worktree registered at path, or nil
+func (s *Snapshot) worktree(path string) *WorktreeInfo {
+	for i := range s.worktrees {
+		if SamePath(path, s.worktrees[i].Path) {
+			return &s.worktrees[i]
+		}
+	}
+	return nil
+}
+
+// Head returns the commit a local branch points to, or "" if it doesn't exist
+func (s *Snapshot) Head(branch string) string {
+	return s.heads[branch]
+}
+
+// LastCommitTime returns the committer date of a branch's head, or the zero
+// time if the branch doesn't exist
+func (s *Snapshot) LastCommitTime(branch string) time.Time {
+	return s.commitTime[branch]
+}
+
+// Statuses computes the status of each target, running the per-worktree
+// 'git status' calls concurrently. Results are in the order of targets.
+func (s *Snapshot) Statuses(targets []StatusTarget) []StatusResult {
+	results := make([]StatusResult, len(targets))
+
+	parallel(len(targets), statusWorkers, func(i int) {
+		info, err := s.status(targets[i])
+		results[i] = StatusResult{Info: info, Err: err}
+	})
+	return results
+}
+
+// status computes the status of a single target
+func (s *Snapshot) status(target StatusTarget) (*StatusInfo, error) {
+	wt := s.worktree(target.Path)
+	if wt == nil {
+		return &StatusInfo{
+			Status:      StatusStale,
+			Description: StatusStale.String(),
+		}, nil
+	}
+
+	info := &StatusInfo{Locked: wt.Locked || target.Locked}
+	if err := readWorkingTree(target.Path, info); err != nil {
+		return nil, err
+	}
+	info.Operation = InProgressOperation(target.Path)
+	if info.Operation == "rebase" {
+		// HEAD is always detached mid-rebase; "rebasing" already says so
+		info.Detached = false
+	}
+
+	head, ok := s.heads[target.Branch]
+	if !ok {
+		info.BranchMissing = true
+		info.describe(false)
+		return info, nil
+	}
+
+	if counts, ok := s.counts[target.Branch]; ok {
+		info.AheadCount, info.BehindCount = counts.ahead, counts.behind
+	} else {
+		// Base branch may not exist; leave the counts at zero like GetAheadCount
+		info.AheadCount, info.BehindCount, _ = GetAheadBehind(s.baseBranch, target.Branch)
+	}
+
+	// Merge checks are skipped for work in progress, which is never reported as
+	// merged, and for branches still at the commit they were created at
+	merged := false
+	if !info.Dirty() && info.Operation == "" && !atCreationPoint(target.Branch, head) {
+		if s.merged[target.Branch] {
+			merged = true
+		} else if info.AheadCount > 0 {
+			info.MergedBy = mergedEquivalent(s.baseBranch, target.Branch, s.baseTree)
+			merged = info.MergedBy != ""
+		}
+	}
+	info.describe(merged)
+	return info, nil
+}
+
+// branchHeads maps every local branch to the commit it points to and that
+// commit's committer date
+func branchHeads() (map[string]string, map[string]time.Time, error) {
+	lines, err := forEachBranch("%(refname)%00%(objectname)%00%(committerdate:unix)")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	heads := make(map[string]string, len(lines))
+	times := make(map[string]time.Time, len(lines))
+	for _, line := range lines {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 3 {
+			continue
+		}
+		name := strings.TrimPrefix(fields[0], "refs/heads/")
+		heads[name] = fields[1]
+		if unix, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+			times[name] = time.Unix(unix, 0)
+		}
+	}
+	return heads, times, nil
+}
+
+// mergedBranches returns the local branches whose tip is reachable from baseBranch
+func mergedBranches(baseBranch string) map[string]bool {
+	merged := make(map[string]bool)
+	lines, err := forEachBranch("%(refname)", "--merged="+baseBranch)
+	if err != nil {
+		return merged
+	}
+	for _, line := range lines {
+		merged[strings.TrimPrefix(line, "refs/heads/")] = true
+	}
+	return merged
+}
+
+// aheadBehindCounts returns the ahead/behind counts of each local branch
+// relative to baseBranch. It returns nil if git is too old for
+// %(ahead-behind) (git < 2.41).
+func aheadBehindCounts(baseBranch string) map[string]aheadBehind {
+	lines, err := forEachBranch("%(refname)%00%(ahead-behind:" + baseBranch + ")")
+	if err != nil {
+		return nil
+	}
+
+	counts := make(map[string]aheadBehind, len(lines))
+	for _, line := range lines {
+		name, value, _ := strings.Cut(line, "\x00")
+		fields := strings.Fields(value)
+		if len(fields) != 2 {
+			continue
+		}
+		ahead, err1 := strconv.Atoi(fields[0])
+		behind, err2 := strconv.Atoi(fields[1])
+		if err1 == nil && err2 == nil {
+			counts[strings.TrimPrefix(name, "refs/heads/")] = aheadBehind{ahead, behind}
+		}
+	}
+	return counts
+}
+
+// forEachBranch runs 'git for-each-ref' over local branches and returns its lines
+func forEachBranch(format string, args ...string) ([]string, error) {
+	cmdArgs := append([]string{"for-each-ref", "--format=" + format}, args...)
+	cmdArgs = append(cmdArgs, "refs/heads")
+	cmd := exec.Command("git", cmdArgs...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// parallel calls fn for 0..n-1 using at most workers goroutines
+func parallel(n, workers int, fn func(i int)) {
+	if workers > n {
+		workers = n
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
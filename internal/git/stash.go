@@ -0,0 +1,47 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// StashCreate records the uncommitted changes to tracked files in dir as a
+// stash commit without touching the worktree or the stash list. It returns
+// "" if there are no changes.
+func StashCreate(dir string) (string, error) {
+	cmd := exec.Command("git", "-C", dir, "stash", "create", "wtree: removed worktree")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to save uncommitted changes: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// StashApply applies a stash commit created by StashCreate to dir, restoring
+// the index as well as the working tree
+func StashApply(dir, commit string) error {
+	cmd := exec.Command("git", "-C", dir, "stash", "apply", "--index", commit)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to apply uncommitted changes: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// UntrackedFiles lists files in dir that are neither tracked nor ignored,
+// relative to dir
+func UntrackedFiles(dir string) ([]string, error) {
+	cmd := exec.Command("git", "-C", dir, "ls-files", "-z", "--others", "--exclude-standard")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+
+	var files []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
@@ -1,7 +1,10 @@
 package git
 
 import (
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
@@ -14,59 +17,249 @@ const (
 	StatusUncommitted
 	StatusAhead
 	StatusMerged
-	StatusStale // Worktree no longer exists
+	StatusStale    // Worktree no longer exists
+	StatusBehind   // Base branch has commits the branch doesn't
+	StatusDiverged // Both ahead of and behind the base branch
 )
 
+// String returns the short name of the status
+func (s WorktreeStatus) String() string {
+	switch s {
+	case StatusClean:
+		return "clean"
+	case StatusUncommitted:
+		return "uncommitted"
+	case StatusAhead:
+		return "ahead"
+	case StatusMerged:
+		return "merged"
+	case StatusStale:
+		return "stale"
+	case StatusBehind:
+		return "behind"
+	case StatusDiverged:
+		return "diverged"
+	default:
+		return "unknown"
+	}
+}
+
 // StatusInfo contains detailed status information
 type StatusInfo struct {
-	Status      WorktreeStatus
-	AheadCount  int
-	Description string
+	Status        WorktreeStatus
+	AheadCount    int
+	BehindCount   int
+	Staged        int    // Files with changes in the index
+	Unstaged      int    // Tracked files with changes in the working tree
+	Untracked     int    // Untracked files and directories
+	Conflicted    int    // Files with unresolved conflicts
+	MergedBy      string // MergedBySquash or MergedByRebase when merged without the branch itself
+	Operation     string // Operation in progress: rebase, merge, cherry-pick, revert or bisect
+	Locked        bool
+	Detached      bool
+	BranchMissing bool
+	Description   string
+}
+
+// Dirty reports whether the worktree has any changes or untracked files
+func (i *StatusInfo) Dirty() bool {
+	return i.Staged+i.Unstaged+i.Untracked+i.Conflicted > 0
+}
+
+// HasTrackedChanges reports whether tracked files have staged, unstaged or
+// conflicting changes; untracked files don't count
+func (i *StatusInfo) HasTrackedChanges() bool {
+	return i.Staged+i.Unstaged+i.Conflicted > 0
+}
+
+// describe sets Status and Description from the other fields. Work in
+// progress takes precedence over merged, which takes precedence over
+// ahead/behind.
+func (i *StatusInfo) describe(merged bool) {
+	var parts []string
+	switch {
+	case i.Operation != "" || i.Dirty():
+		i.Status = StatusUncommitted
+		if i.Operation != "" {
+			parts = append(parts, operationLabel(i.Operation))
+		}
+		if i.Dirty() {
+			parts = append(parts, "uncommitted")
+		}
+	case merged:
+		i.Status = StatusMerged
+		parts = append(parts, "merged")
+	case i.AheadCount > 0 && i.BehindCount > 0:
+		i.Status = StatusDiverged
+	case i.AheadCount > 0:
+		i.Status = StatusAhead
+	case i.BehindCount > 0:
+		i.Status = StatusBehind
+	default:
+		i.Status = StatusClean
+	}
+
+	if i.Status != StatusMerged {
+		if i.AheadCount > 0 {
+			parts = append(parts, "ahead "+strconv.Itoa(i.AheadCount))
+		}
+		if i.BehindCount > 0 {
+			parts = append(parts, "behind "+strconv.Itoa(i.BehindCount))
+		}
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "clean")
+	}
+
+	// Details go in parentheses, docker ps style: "uncommitted, ahead 2 (1 staged, locked)"
+	var details []string
+	if i.Status == StatusMerged && i.MergedBy != "" {
+		details = append(details, i.MergedBy)
+	}
+	for _, c := range []struct {
+		n    int
+		name string
+	}{
+		{i.Conflicted, "conflicted"},
+		{i.Staged, "staged"},
+		{i.Unstaged, "modified"},
+		{i.Untracked, "untracked"},
+	} {
+		if c.n > 0 {
+			details = append(details, strconv.Itoa(c.n)+" "+c.name)
+		}
+	}
+	if i.BranchMissing {
+		details = append(details, "branch missing")
+	}
+	if i.Detached {
+		details = append(details, "detached")
+	}
+	if i.Locked {
+		details = append(details, "locked")
+	}
+
+	i.Description = strings.Join(parts, ", ")
+	if len(details) > 0 {
+		i.Description += " (" + strings.Join(details, ", ") + ")"
+	}
+}
+
+// operationLabel returns the progressive form of an operation name
+func operationLabel(op string) string {
+	switch op {
+	case "rebase":
+		return "rebasing"
+	case "merge":
+		return "merging"
+	case "cherry-pick":
+		return "cherry-picking"
+	case "revert":
+		return "reverting"
+	case "bisect":
+		return "bisecting"
+	default:
+		return op
+	}
 }
 
 // GetStatus returns the status of a worktree
 func GetStatus(worktreePath, baseBranch, branch string) (*StatusInfo, error) {
-	// Check for uncommitted changes
-	hasChanges, err := HasUncommittedChanges(worktreePath)
+	snap, err := NewSnapshot(baseBranch)
 	if err != nil {
 		return nil, err
 	}
-	if hasChanges {
-		return &StatusInfo{
-			Status:      StatusUncommitted,
-			Description: "uncommitted",
-		}, nil
+	return snap.status(StatusTarget{Path: worktreePath, Branch: branch})
+}
+
+// readWorkingTree fills the change counts and detached flag of info from
+// 'git status --porcelain=v2 --branch'
+func readWorkingTree(worktreePath string, info *StatusInfo) error {
+	cmd := exec.Command("git", "-C", worktreePath, "status", "--porcelain=v2", "--branch")
+	output, err := cmd.Output()
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "#":
+			if fields[1] == "branch.head" && len(fields) > 2 && fields[2] == "(detached)" {
+				info.Detached = true
+			}
+		case "1", "2":
+			// XY: index status, then working tree status; "." means unchanged
+			if fields[1][0] != '.' {
+				info.Staged++
+			}
+			if len(fields[1]) > 1 && fields[1][1] != '.' {
+				info.Unstaged++
+			}
+		case "u":
+			info.Conflicted++
+		case "?":
+			info.Untracked++
+		}
 	}
+	return nil
+}
 
-	// Check if merged
-	merged, err := IsMerged(baseBranch, branch)
+// InProgressOperation returns the operation (rebase, merge, cherry-pick,
+// revert or bisect) stopped in the worktree at path, or "" if there is none
+func InProgressOperation(worktreePath string) string {
+	gitDir, err := worktreeGitDir(worktreePath)
 	if err != nil {
-		return nil, err
+		return ""
+	}
+
+	markers := []struct {
+		file string
+		op   string
+	}{
+		{"rebase-merge", "rebase"},
+		{"rebase-apply", "rebase"},
+		{"MERGE_HEAD", "merge"},
+		{"CHERRY_PICK_HEAD", "cherry-pick"},
+		{"REVERT_HEAD", "revert"},
+		{"BISECT_LOG", "bisect"},
 	}
-	if merged {
-		return &StatusInfo{
-			Status:      StatusMerged,
-			Description: "merged",
-		}, nil
+	for _, m := range markers {
+		if _, err := os.Stat(filepath.Join(gitDir, m.file)); err == nil {
+			return m.op
+		}
 	}
+	return ""
+}
 
-	// Check ahead count
-	aheadCount, err := GetAheadCount(baseBranch, branch)
+// worktreeGitDir resolves the git directory of a worktree from its .git
+// entry without spawning git: a directory for the main worktree, or a
+// "gitdir: <path>" file for linked ones
+func worktreeGitDir(worktreePath string) (string, error) {
+	dotGit := filepath.Join(worktreePath, ".git")
+	fi, err := os.Stat(dotGit)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	if aheadCount > 0 {
-		return &StatusInfo{
-			Status:      StatusAhead,
-			AheadCount:  aheadCount,
-			Description: "ahead " + strconv.Itoa(aheadCount),
-		}, nil
+	if fi.IsDir() {
+		return dotGit, nil
 	}
 
-	return &StatusInfo{
-		Status:      StatusClean,
-		Description: "clean",
-	}, nil
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", err
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return "", fmt.Errorf("invalid .git file: %s", dotGit)
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(worktreePath, gitDir)
+	}
+	return gitDir, nil
 }
 
 // HasUncommittedChanges checks if there are uncommitted changes in the worktree
@@ -79,6 +272,12 @@ func HasUncommittedChanges(worktreePath string) (bool, error) {
 	return len(strings.TrimSpace(string(output))) > 0, nil
 }
 
+// HasStagedChanges checks if the index in dir differs from HEAD
+func HasStagedChanges(dir string) bool {
+	cmd := exec.Command("git", "-C", dir, "diff", "--cached", "--quiet")
+	return cmd.Run() != nil
+}
+
 // GetAheadCount returns the number of commits ahead of base branch
 func GetAheadCount(baseBranch, branch string) (int, error) {
 	cmd := exec.Command("git", "rev-list", "--count", baseBranch+".."+branch)
@@ -94,20 +293,104 @@ func GetAheadCount(baseBranch, branch string) (int, error) {
 	return count, nil
 }
 
-// IsMerged checks if the branch has been merged into base branch
-func IsMerged(baseBranch, branch string) (bool, error) {
-	cmd := exec.Command("git", "branch", "--merged", baseBranch)
+// GetAheadBehind returns how many commits branch has that baseBranch doesn't
+// (ahead) and how many baseBranch has that branch doesn't (behind)
+func GetAheadBehind(baseBranch, branch string) (ahead, behind int, err error) {
+	cmd := exec.Command("git", "rev-list", "--left-right", "--count", baseBranch+"..."+branch)
 	output, err := cmd.Output()
 	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count commits: %w", err)
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %s", strings.TrimSpace(string(output)))
+	}
+	behind, _ = strconv.Atoi(fields[0])
+	ahead, _ = strconv.Atoi(fields[1])
+	return ahead, behind, nil
+}
+
+// Ways a branch's changes can land in base without the branch being merged
+const (
+	MergedBySquash = "squash"
+	MergedByRebase = "rebase"
+)
+
+// IsMerged checks if the branch has been merged into base branch: its tip is
+// reachable from base, or its changes were squash- or rebase-merged. A branch
+// still at the commit it was created at is not merged, so a fresh worktree
+// isn't mistaken for a finished one.
+func IsMerged(baseBranch, branch string) (bool, error) {
+	head, err := RevParse("refs/heads/" + branch)
+	if err != nil {
+		return false, nil
+	}
+	if atCreationPoint(branch, head) {
 		return false, nil
 	}
+	if IsAncestor(head, baseBranch) {
+		return true, nil
+	}
+	return mergedEquivalent(baseBranch, branch, treeOf(baseBranch)) != "", nil
+}
+
+// treeOf returns the tree hash of ref, or "" if it can't be resolved
+func treeOf(ref string) string {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{tree}")
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		branchName := strings.TrimSpace(strings.TrimPrefix(line, "*"))
-		if branchName == branch {
-			return true, nil
+// mergedEquivalent reports how branch's changes reached baseBranch when the
+// branch itself is not an ancestor: MergedByRebase if every commit has a
+// patch-equivalent commit in base ('git cherry'), MergedBySquash if merging
+// the branch would leave baseTree unchanged. It returns "" otherwise.
+func mergedEquivalent(baseBranch, branch, baseTree string) string {
+	cmd := exec.Command("git", "cherry", baseBranch, branch)
+	if output, err := cmd.Output(); err == nil {
+		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+		picked := lines[0] != ""
+		for _, line := range lines {
+			if !strings.HasPrefix(line, "-") {
+				picked = false
+				break
+			}
+		}
+		if picked {
+			return MergedByRebase
 		}
 	}
-	return false, nil
+
+	if baseTree == "" {
+		return ""
+	}
+	// Exits non-zero on conflicts, in which case base can't contain the branch
+	cmd = exec.Command("git", "merge-tree", "--write-tree", "--no-messages", baseBranch, branch)
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	if tree, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n"); tree == baseTree {
+		return MergedBySquash
+	}
+	return ""
+}
+
+// atCreationPoint reports whether branch still points at head, the commit it
+// was created at according to its reflog. Without a reflog (expired, or
+// core.logAllRefUpdates=false) the branch is assumed to have moved.
+func atCreationPoint(branch, head string) bool {
+	cmd := exec.Command("git", "reflog", "--format=%H", "refs/heads/"+branch)
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	entries := strings.Fields(string(output))
+	if len(entries) == 0 {
+		return false
+	}
+	return entries[len(entries)-1] == head
 }
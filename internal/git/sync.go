@@ -0,0 +1,84 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrUntrackedOverwritten is returned when an update would overwrite untracked
+// files with files coming from the other branch
+var ErrUntrackedOverwritten = errors.New("untracked files would be overwritten")
+
+// Upstream describes the remote-tracking branch a local branch follows
+type Upstream struct {
+	Remote    string // Remote name, e.g. origin
+	RemoteRef string // Branch on the remote, e.g. refs/heads/main
+	Ref       string // Local remote-tracking ref, e.g. origin/main
+}
+
+// GetUpstream returns the upstream of a local branch, or false if it has none
+func GetUpstream(branch string) (Upstream, bool) {
+	cmd := exec.Command("git", "for-each-ref",
+		"--format=%(upstream:remotename)%00%(upstream:remoteref)%00%(upstream:short)",
+		"refs/heads/"+branch)
+	output, err := cmd.Output()
+	if err != nil {
+		return Upstream{}, false
+	}
+
+	fields := strings.Split(strings.TrimSpace(string(output)), "\x00")
+	if len(fields) != 3 || fields[0] == "" || fields[2] == "" {
+		return Upstream{}, false
+	}
+	return Upstream{Remote: fields[0], RemoteRef: fields[1], Ref: fields[2]}, true
+}
+
+// Fetch fetches a single ref from a remote, updating its remote-tracking ref
+func Fetch(remote, ref string) error {
+	cmd := exec.Command("git", "fetch", "--quiet", remote, ref)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to fetch %s from %s: %s", ref, remote, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// UpdateWorktree brings the branch checked out in dir up to date with onto by
+// rebasing (or merging if rebase is false). On conflict the operation is
+// aborted, leaving the worktree as it was, and ErrMergeConflict is returned;
+// ErrUntrackedOverwritten if untracked files are in the way.
+// With autostash, local changes are stashed around the update; stashConflict
+// reports that they could not be reapplied and were kept in the stash.
+func UpdateWorktree(dir, onto string, rebase, autostash bool) (stashConflict bool, err error) {
+	args := []string{"-C", dir}
+	if rebase {
+		args = append(args, "rebase")
+	} else {
+		args = append(args, "merge", "--no-edit")
+	}
+	if autostash {
+		args = append(args, "--autostash")
+	}
+	args = append(args, onto)
+
+	cmd := exec.Command("git", args...)
+	output, err := cmd.CombinedOutput()
+	outputStr := strings.TrimSpace(string(output))
+	if err != nil {
+		// Aborting also restores autostashed changes
+		if rebase {
+			exec.Command("git", "-C", dir, "rebase", "--abort").Run()
+		} else if IsMergeInProgress(dir) {
+			exec.Command("git", "-C", dir, "merge", "--abort").Run()
+		}
+		if strings.Contains(outputStr, "CONFLICT") || strings.Contains(outputStr, "could not apply") {
+			return false, ErrMergeConflict
+		}
+		if strings.Contains(outputStr, "untracked working tree files would be overwritten") {
+			return false, ErrUntrackedOverwritten
+		}
+		return false, fmt.Errorf("failed to update: %s", outputStr)
+	}
+	return strings.Contains(outputStr, "Applying autostash resulted in conflicts"), nil
+}
@@ -72,6 +72,25 @@ func AddWorktree(path, branch, baseBranch string) error {
 	return nil
 }
 
+// AddWorktreeForBranch creates a new worktree that checks out an existing branch.
+// If only a remote branch of that name exists, git creates a local tracking branch.
+func AddWorktreeForBranch(path, branch string) error {
+	cmd := exec.Command("git", "worktree", "add", path, branch)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create worktree: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// AddDetachedWorktree creates a new worktree with a detached HEAD at ref
+func AddDetachedWorktree(path, ref string) error {
+	cmd := exec.Command("git", "worktree", "add", "--detach", path, ref)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create worktree: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 // RemoveWorktree removes a worktree
 func RemoveWorktree(path string, force bool) error {
 	args := []string{"worktree", "remove", path}
@@ -85,35 +104,173 @@ func RemoveWorktree(path string, force bool) error {
 	return nil
 }
 
-// WorktreeExists checks if a worktree exists at the given path
-func WorktreeExists(path string) bool {
-	absPath, err := filepath.Abs(path)
+// LockWorktree locks a worktree with 'git worktree lock', which keeps git from
+// pruning or removing it. An empty reason is omitted.
+func LockWorktree(path, reason string) error {
+	args := []string{"worktree", "lock"}
+	if reason != "" {
+		args = append(args, "--reason", reason)
+	}
+	cmd := exec.Command("git", append(args, path)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to lock worktree: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// WorktreeLock reports whether the worktree at path is locked and why
+func WorktreeLock(path string) (bool, string) {
+	worktrees, err := ListWorktrees()
 	if err != nil {
-		return false
+		return false, ""
+	}
+	for _, wt := range worktrees {
+		if SamePath(path, wt.Path) {
+			return wt.Locked, wt.LockReason
+		}
+	}
+	return false, ""
+}
+
+// UnlockWorktree unlocks a worktree locked with 'git worktree lock'
+func UnlockWorktree(path string) error {
+	cmd := exec.Command("git", "worktree", "unlock", path)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to unlock worktree: %s", strings.TrimSpace(string(output)))
 	}
-	// Normalize path separators for comparison (git uses forward slashes on Windows)
-	normalizedPath := filepath.ToSlash(absPath)
+	return nil
+}
 
+// WorktreeInfo is one entry of 'git worktree list --porcelain'
+type WorktreeInfo struct {
+	Path       string
+	Head       string
+	Branch     string // Short branch name; empty when detached or bare
+	Bare       bool
+	Detached   bool
+	Locked     bool
+	LockReason string
+	Prunable   bool
+}
+
+// ListWorktrees returns all worktrees of the repository, main worktree first
+func ListWorktrees() ([]WorktreeInfo, error) {
 	cmd := exec.Command("git", "worktree", "list", "--porcelain")
 	output, err := cmd.Output()
 	if err != nil {
-		return false
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
 	}
+	return parseWorktreeList(string(output)), nil
+}
+
+// parseWorktreeList parses porcelain output; entries are separated by blank lines
+func parseWorktreeList(output string) []WorktreeInfo {
+	var result []WorktreeInfo
+	var current *WorktreeInfo
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		key, value, _ := strings.Cut(line, " ")
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "worktree ") {
-			wtPath := strings.TrimPrefix(line, "worktree ")
-			// Normalize git's path as well
-			normalizedWtPath := filepath.ToSlash(wtPath)
-			if strings.EqualFold(normalizedPath, normalizedWtPath) {
-				return true
+		switch key {
+		case "worktree":
+			result = append(result, WorktreeInfo{Path: value})
+			current = &result[len(result)-1]
+		case "HEAD":
+			if current != nil {
+				current.Head = value
+			}
+		case "branch":
+			if current != nil {
+				current.Branch = strings.TrimPrefix(value, "refs/heads/")
+			}
+		case "bare":
+			if current != nil {
+				current.Bare = true
+			}
+		case "detached":
+			if current != nil {
+				current.Detached = true
+			}
+		case "locked":
+			if current != nil {
+				current.Locked = true
+				current.LockReason = value
 			}
+		case "prunable":
+			if current != nil {
+				current.Prunable = true
+			}
+		}
+	}
+	return result
+}
+
+// SamePath reports whether two paths refer to the same location, ignoring
+// separator style and case (git uses forward slashes on Windows)
+func SamePath(a, b string) bool {
+	absA, err := filepath.Abs(a)
+	if err != nil {
+		return false
+	}
+	absB, err := filepath.Abs(b)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(filepath.ToSlash(absA), filepath.ToSlash(absB))
+}
+
+// WorktreeExists checks if a worktree exists at the given path
+func WorktreeExists(path string) bool {
+	worktrees, err := ListWorktrees()
+	if err != nil {
+		return false
+	}
+
+	for _, wt := range worktrees {
+		if SamePath(path, wt.Path) {
+			return true
 		}
 	}
 	return false
 }
 
+// FindBranchWorktree returns the path of the worktree that has branch checked out
+func FindBranchWorktree(branch string) (string, bool) {
+	worktrees, err := ListWorktrees()
+	if err != nil {
+		return "", false
+	}
+
+	for _, wt := range worktrees {
+		if wt.Branch == branch {
+			return filepath.FromSlash(wt.Path), true
+		}
+	}
+	return "", false
+}
+
+// WorktreeHead returns the commit checked out in the worktree at path
+func WorktreeHead(path string) (string, error) {
+	cmd := exec.Command("git", "-C", path, "rev-parse", "--verify", "--quiet", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD in %s", path)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// WorktreeAdminDir returns the worktree's administrative directory
+// (.git/worktrees/<name> for linked worktrees)
+func WorktreeAdminDir(worktreePath string) (string, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "rev-parse", "--absolute-git-dir")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get git directory: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 // PruneWorktrees runs git worktree prune to clean up stale worktree entries
 func PruneWorktrees() error {
 	cmd := exec.Command("git", "worktree", "prune")
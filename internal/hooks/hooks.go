@@ -0,0 +1,92 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/satoruhiga/wtree/internal/config"
+	"github.com/satoruhiga/wtree/internal/shell"
+)
+
+// Event is a worktree lifecycle event
+type Event string
+
+const (
+	PreCreate  Event = "pre-create"
+	PostCreate Event = "post-create"
+	PreRemove  Event = "pre-remove"
+	PostRemove Event = "post-remove"
+	PreMerge   Event = "pre-merge"
+	PostMerge  Event = "post-merge"
+)
+
+// Context describes the worktree a hook runs for.
+// It is exposed to hook commands as WTREE_* environment variables.
+type Context struct {
+	RepoRoot   string
+	ID         string
+	Name       string
+	Branch     string
+	Path       string
+	BaseBranch string
+	Profile    string
+}
+
+// Commands returns the commands configured for an event
+func Commands(cfg config.HooksConfig, event Event) []string {
+	switch event {
+	case PreCreate:
+		return cfg.PreCreate
+	case PostCreate:
+		return cfg.PostCreate
+	case PreRemove:
+		return cfg.PreRemove
+	case PostRemove:
+		return cfg.PostRemove
+	case PreMerge:
+		return cfg.PreMerge
+	case PostMerge:
+		return cfg.PostMerge
+	default:
+		return nil
+	}
+}
+
+// Run runs the commands configured for an event in order and stops at the
+// first failure. Commands run in the worktree if it exists, otherwise in the
+// repository root.
+func Run(cfg config.HooksConfig, event Event, ctx Context) error {
+	commands := Commands(cfg, event)
+	if len(commands) == 0 {
+		return nil
+	}
+
+	dir := ctx.RepoRoot
+	if info, err := os.Stat(ctx.Path); err == nil && info.IsDir() {
+		dir = ctx.Path
+	}
+
+	env := append(os.Environ(),
+		"WTREE_HOOK="+string(event),
+		"WTREE_ID="+ctx.ID,
+		"WTREE_NAME="+ctx.Name,
+		"WTREE_BRANCH="+ctx.Branch,
+		"WTREE_PATH="+ctx.Path,
+		"WTREE_BASE_BRANCH="+ctx.BaseBranch,
+		"WTREE_PROFILE="+ctx.Profile,
+		"WTREE_REPO_ROOT="+ctx.RepoRoot,
+	)
+
+	for _, cmdStr := range commands {
+		fmt.Printf("Running %s hook: %s\n", event, cmdStr)
+		cmd := shell.Command(cmdStr)
+		cmd.Dir = dir
+		cmd.Env = env
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s hook failed: %s: %w", event, cmdStr, err)
+		}
+	}
+	return nil
+}
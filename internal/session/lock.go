@@ -0,0 +1,40 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/satoruhiga/wtree/internal/config"
+)
+
+const lockFile = "sessions.lock"
+
+// lockPath returns the full path to the advisory lock file
+func (s *Store) lockPath() string {
+	return filepath.Join(s.repoRoot, worktreeDir, lockFile)
+}
+
+// lock takes an exclusive advisory lock on the .wtree directory, blocking
+// until it is available. The returned function releases the lock.
+func (s *Store) lock() (func(), error) {
+	// Creates .wtree if needed, keeping the local state in it out of git
+	if err := config.WriteIgnoreFile(s.repoRoot); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(s.lockPath(), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := lockFileHandle(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock sessions: %w", err)
+	}
+
+	return func() {
+		unlockFileHandle(f)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,141 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Version is the current format of sessions.json
+const Version = 1
+
+// migration upgrades the decoded sessions.json from the previous version
+type migration struct {
+	version     int // Version the migration upgrades to
+	description string
+	migrate     func(doc map[string]any) (map[string]any, error)
+}
+
+// migrations are applied in order to files older than their version
+var migrations = []migration{
+	{
+		version:     1,
+		description: "wrap the sessions in an object with a version",
+		migrate: func(doc map[string]any) (map[string]any, error) {
+			// Version 0 is a bare object mapping IDs to sessions
+			return map[string]any{"sessions": doc}, nil
+		},
+	},
+}
+
+// file is the layout of sessions.json
+type file struct {
+	Version  int                 `json:"version"`
+	Sessions map[string]*Session `json:"sessions"`
+}
+
+// decodeFile parses sessions.json, upgrading older versions
+func decodeFile(data []byte) (map[string]*Session, error) {
+	data, _, err := migrate(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse sessions file: %w", err)
+	}
+	if f.Sessions == nil {
+		f.Sessions = make(map[string]*Session)
+	}
+	return f.Sessions, nil
+}
+
+// encodeFile formats sessions as the current version of sessions.json
+func encodeFile(sessions map[string]*Session) ([]byte, error) {
+	data, err := json.MarshalIndent(file{Version: Version, Sessions: sessions}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sessions: %w", err)
+	}
+	return data, nil
+}
+
+// migrate upgrades sessions.json to the current version and returns it with
+// the descriptions of the migrations applied. Up-to-date data is returned as is.
+func migrate(data []byte) ([]byte, []string, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse sessions file: %w", err)
+	}
+
+	version := 0
+	if v, ok := doc["version"].(float64); ok {
+		version = int(v)
+	}
+	if version > Version {
+		return nil, nil, fmt.Errorf("sessions file has version %d, but this wtree only knows up to %d; upgrade wtree", version, Version)
+	}
+	if version == Version {
+		return data, nil, nil
+	}
+
+	var applied []string
+	for _, m := range migrations {
+		if m.version <= version {
+			continue
+		}
+		var err error
+		if doc, err = m.migrate(doc); err != nil {
+			return nil, nil, fmt.Errorf("failed to migrate sessions file to version %d: %w", m.version, err)
+		}
+		doc["version"] = m.version
+		applied = append(applied, m.description)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal sessions: %w", err)
+	}
+	return data, applied, nil
+}
+
+// Migrate upgrades sessions.json to the current version while holding the
+// .wtree lock. It returns the file before and after the upgrade (equal when
+// it is up to date) and the migrations applied. With dryRun nothing is written.
+func (s *Store) Migrate(dryRun bool) (before, after []byte, applied []string, err error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer unlock()
+
+	before, err = os.ReadFile(s.sessionsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil, nil
+		}
+		return nil, nil, nil, fmt.Errorf("failed to read sessions file: %w", err)
+	}
+
+	migrated, applied, err := migrate(before)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(applied) == 0 {
+		return before, before, nil, nil
+	}
+
+	sessions, err := decodeFile(migrated)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if after, err = encodeFile(sessions); err != nil {
+		return nil, nil, nil, err
+	}
+	if !dryRun {
+		if err := writeFileAtomic(s.sessionsPath(), after, 0644); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to write sessions file: %w", err)
+		}
+	}
+	return before, after, applied, nil
+}
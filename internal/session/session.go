@@ -2,16 +2,48 @@ package session
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 )
 
 // Session represents a single worktree session
 type Session struct {
 	ID        string    `json:"id"`
+	Name      string    `json:"name,omitempty"`
 	Branch    string    `json:"branch"`
 	Path      string    `json:"path"`
 	AbsPath   string    `json:"abs_path"`
 	CreatedAt time.Time `json:"created_at"`
+	// ExistingBranch is true when the worktree was attached to a branch that
+	// wtree did not create, so removing the worktree must keep the branch
+	ExistingBranch bool `json:"existing_branch,omitempty"`
+	// PendingMerge is set while a 'wtree merge' is stopped on conflicts
+	PendingMerge *MergeState `json:"pending_merge,omitempty"`
+	// Verification is the result of the last merge.verify run
+	Verification *Verification `json:"verification,omitempty"`
+	// Lock is set while the worktree is locked with 'wtree lock'
+	Lock *LockState `json:"lock,omitempty"`
+	// Profile is the config profile the worktree was created with
+	// (wtree new --profile); later commands on the worktree use it too
+	Profile string `json:"profile,omitempty"`
+}
+
+// LockState records why and when a worktree was locked
+type LockState struct {
+	Reason   string    `json:"reason,omitempty"`
+	LockedAt time.Time `json:"locked_at"`
+}
+
+// MergeState records a merge that stopped on conflicts
+type MergeState struct {
+	Target     string    `json:"target"`
+	TargetHead string    `json:"target_head"` // Commit the target pointed to when the merge stopped
+	Strategy   string    `json:"strategy"`
+	Dir        string    `json:"dir"`                 // Where the conflicted merge lives
+	Temporary  bool      `json:"temporary,omitempty"` // Dir is a worktree created for the merge
+	Message    string    `json:"message,omitempty"`   // Commit message for squash merges
+	StartedAt  time.Time `json:"started_at"`
 }
 
 // NewSession creates a new Session
@@ -25,6 +57,52 @@ func NewSession(id, branch, path, absPath string) *Session {
 	}
 }
 
+// namePattern restricts names to characters that are safe in branch names and paths
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateName checks that a worktree name is well-formed
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) || len(name) > 64 {
+		return fmt.Errorf("invalid name '%s': use letters, digits, '.', '_' and '-' (max 64 characters)", name)
+	}
+	// Git refuses these in ref names
+	if strings.Contains(name, "..") || strings.HasSuffix(name, ".") || strings.HasSuffix(name, ".lock") {
+		return fmt.Errorf("invalid name '%s': git doesn't allow '..' or a trailing '.' or '.lock' in branch names", name)
+	}
+	return nil
+}
+
+// Verification records a run of the merge.verify commands
+type Verification struct {
+	Passed  bool         `json:"passed"`
+	Commit  string       `json:"commit"`            // Branch head that was verified
+	Rebased bool         `json:"rebased,omitempty"` // Verified after a trial rebase onto the target
+	RunAt   time.Time    `json:"run_at"`
+	Steps   []VerifyStep `json:"steps"`
+	Reason  string       `json:"reason,omitempty"` // Why verification failed before running commands
+}
+
+// VerifyStep is the result of one verify command
+type VerifyStep struct {
+	Command    string        `json:"command"`
+	ExitCode   int           `json:"exit_code"`
+	Duration   time.Duration `json:"duration"`
+	OutputTail string        `json:"output_tail,omitempty"`
+}
+
+// OwnsBranch reports whether wtree created the branch and may delete it
+func (s *Session) OwnsBranch() bool {
+	return !s.ExistingBranch
+}
+
+// DisplayName returns the name if set, otherwise the ID
+func (s *Session) DisplayName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.ID
+}
+
 // RelativeTime returns a human-readable relative time string
 func (s *Session) RelativeTime() string {
 	d := time.Since(s.CreatedAt)
@@ -1,7 +1,6 @@
 package session
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -48,32 +47,85 @@ func (s *Store) Load() error {
 		return fmt.Errorf("failed to read sessions file: %w", err)
 	}
 
-	if err := json.Unmarshal(data, &s.sessions); err != nil {
-		return fmt.Errorf("failed to parse sessions file: %w", err)
+	// Older formats are upgraded in memory and written back on the next Save
+	sessions, err := decodeFile(data)
+	if err != nil {
+		return err
 	}
+	s.sessions = sessions
 
 	return nil
 }
 
-// Save writes sessions to sessions.json
+// Save writes sessions to sessions.json.
+// The file is written to a temporary file and renamed into place, so readers
+// never see a partial write. Use Update to modify sessions safely.
 func (s *Store) Save() error {
 	// Ensure .wtree directory exists
 	if err := os.MkdirAll(s.worktreeDirPath(), 0755); err != nil {
 		return fmt.Errorf("failed to create .wtree directory: %w", err)
 	}
 
-	data, err := json.MarshalIndent(s.sessions, "", "  ")
+	data, err := encodeFile(s.sessions)
 	if err != nil {
-		return fmt.Errorf("failed to marshal sessions: %w", err)
+		return err
 	}
 
-	if err := os.WriteFile(s.sessionsPath(), data, 0644); err != nil {
+	if err := writeFileAtomic(s.sessionsPath(), data, 0644); err != nil {
 		return fmt.Errorf("failed to write sessions file: %w", err)
 	}
 
 	return nil
 }
 
+// writeFileAtomic writes data to a temporary file in the same directory and
+// renames it over path
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, perm)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// Update runs a load-modify-save cycle while holding the .wtree lock.
+// Sessions are reloaded from disk before fn runs, so changes made by other
+// wtree processes since the last Load are kept.
+func (s *Store) Update(fn func(s *Store) error) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := s.Load(); err != nil {
+		return err
+	}
+	if err := fn(s); err != nil {
+		return err
+	}
+	return s.Save()
+}
+
 // Add adds a new session
 func (s *Store) Add(session *Session) {
 	s.sessions[session.ID] = session
@@ -90,12 +142,44 @@ func (s *Store) Get(id string) (*Session, bool) {
 	return session, ok
 }
 
-// FindByPartialID finds a session by partial ID match
+// FindByName returns a session by exact name
+func (s *Store) FindByName(name string) (*Session, bool) {
+	for _, session := range s.sessions {
+		if session.Name != "" && session.Name == name {
+			return session, true
+		}
+	}
+	return nil, false
+}
+
+// NameInUse reports whether name is already the name or ID of a session other than exceptID
+func (s *Store) NameInUse(name, exceptID string) bool {
+	for id, session := range s.sessions {
+		if id == exceptID {
+			continue
+		}
+		if id == name || session.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FindByPartialID finds a session by ID or name.
+// Exact ID and name matches win; otherwise the query is matched as a prefix
+// of both IDs and names.
 func (s *Store) FindByPartialID(partialID string) (*Session, error) {
+	if session, ok := s.sessions[partialID]; ok {
+		return session, nil
+	}
+	if session, ok := s.FindByName(partialID); ok {
+		return session, nil
+	}
+
 	var matches []*Session
 
 	for id, session := range s.sessions {
-		if strings.HasPrefix(id, partialID) {
+		if strings.HasPrefix(id, partialID) || (session.Name != "" && strings.HasPrefix(session.Name, partialID)) {
 			matches = append(matches, session)
 		}
 	}
@@ -0,0 +1,15 @@
+package shell
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// Command returns a command that runs cmdStr through the platform shell
+// (cmd /c on Windows, sh -c elsewhere)
+func Command(cmdStr string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/c", cmdStr)
+	}
+	return exec.Command("sh", "-c", cmdStr)
+}
@@ -0,0 +1,96 @@
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// OpenMode represents how to open the terminal
+type OpenMode string
+
+const (
+	ModeTab    OpenMode = "tab"
+	ModePane   OpenMode = "pane"
+	ModeWindow OpenMode = "window"
+)
+
+// ParseMode validates an open mode name
+func ParseMode(name string) (OpenMode, error) {
+	switch m := OpenMode(name); m {
+	case ModeTab, ModePane, ModeWindow:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown terminal mode '%s' (use tab, pane or window)", name)
+}
+
+// Backend is a terminal driver that can open worktrees
+type Backend interface {
+	// Name returns the human-readable name of the terminal
+	Name() string
+	// IsAvailable checks if the terminal can be used on this machine
+	IsAvailable() bool
+	// Open opens the given path in a new tab, pane or window
+	Open(path string, mode OpenMode, execCmd string) error
+	// Close closes tabs/panes whose working directory is the given path.
+	// Backends that cannot do this return nil.
+	Close(path string) error
+}
+
+// BackendAuto selects the backend from the environment
+const BackendAuto = "auto"
+
+var backends = make(map[string]Backend)
+
+// Register adds a backend to the registry under the given config name
+func Register(name string, backend Backend) {
+	backends[name] = backend
+}
+
+// Names returns the config names of all registered backends
+func Names() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the backend registered under the given name
+func Get(name string) (Backend, error) {
+	backend, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown terminal backend '%s' (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return backend, nil
+}
+
+// Select returns the backend for the given config value.
+// An empty value or "auto" detects the backend from the environment.
+func Select(name string) (Backend, error) {
+	if name == "" || name == BackendAuto {
+		return Get(Detect())
+	}
+	return Get(name)
+}
+
+// Detect returns the name of the backend for the current environment
+func Detect() string {
+	switch {
+	case os.Getenv("TMUX") != "":
+		return "tmux"
+	case os.Getenv("ZELLIJ") != "":
+		return "zellij"
+	case os.Getenv("WEZTERM_PANE") != "":
+		return "wezterm"
+	case os.Getenv("KITTY_WINDOW_ID") != "":
+		return "kitty"
+	case runtime.GOOS == "windows":
+		return "wt"
+	default:
+		return "tmux"
+	}
+}
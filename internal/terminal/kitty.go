@@ -0,0 +1,60 @@
+package terminal
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// kitty opens worktrees through kitty remote control (allow_remote_control must be enabled)
+type kitty struct{}
+
+func init() {
+	Register("kitty", kitty{})
+}
+
+func (kitty) Name() string {
+	return "kitty"
+}
+
+func (kitty) IsAvailable() bool {
+	_, err := exec.LookPath("kitty")
+	return err == nil
+}
+
+func (kitty) Open(path string, mode OpenMode, execCmd string) error {
+	// kitty calls splits "windows" and OS windows "os-windows"
+	launchType := "tab"
+	switch mode {
+	case ModePane:
+		launchType = "window"
+	case ModeWindow:
+		launchType = "os-window"
+	}
+
+	args := []string{"@", "launch", "--type=" + launchType, "--cwd=" + path}
+	if execCmd != "" {
+		args = append(args, "sh", "-c", execCmd)
+	}
+
+	cmd := exec.Command("kitty", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to open kitty: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Close closes every kitty window whose working directory is inside the given path
+func (kitty) Close(path string) error {
+	match := "cwd:^" + regexp.QuoteMeta(path) + "(/|$)"
+	cmd := exec.Command("kitty", "@", "close-window", "--match", match)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		outputStr := strings.TrimSpace(string(output))
+		if strings.Contains(outputStr, "No matching windows") {
+			return nil
+		}
+		return fmt.Errorf("failed to close kitty window: %s", outputStr)
+	}
+	return nil
+}
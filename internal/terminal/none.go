@@ -0,0 +1,24 @@
+package terminal
+
+// none never opens a terminal; callers print the worktree path instead
+type none struct{}
+
+func init() {
+	Register("none", none{})
+}
+
+func (none) Name() string {
+	return "none"
+}
+
+func (none) IsAvailable() bool {
+	return false
+}
+
+func (none) Open(path string, mode OpenMode, execCmd string) error {
+	return nil
+}
+
+func (none) Close(path string) error {
+	return nil
+}
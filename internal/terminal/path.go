@@ -0,0 +1,18 @@
+package terminal
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// isWithin reports whether path is dir or a descendant of it
+func isWithin(path, dir string) bool {
+	if path == "" {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
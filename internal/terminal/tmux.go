@@ -0,0 +1,65 @@
+package terminal
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// tmux opens worktrees in tmux panes/windows
+type tmux struct{}
+
+func init() {
+	Register("tmux", tmux{})
+}
+
+func (tmux) Name() string {
+	return "tmux"
+}
+
+func (tmux) IsAvailable() bool {
+	_, err := exec.LookPath("tmux")
+	return err == nil
+}
+
+func (tmux) Open(path string, mode OpenMode, execCmd string) error {
+	var args []string
+
+	switch mode {
+	case ModePane:
+		args = []string{"split-window", "-c", path}
+	default:
+		args = []string{"new-window", "-c", path}
+	}
+
+	if execCmd != "" {
+		args = append(args, execCmd)
+	}
+
+	cmd := exec.Command("tmux", args...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open tmux: %w", err)
+	}
+	return nil
+}
+
+// Close kills every pane whose current path is inside the given path
+func (tmux) Close(path string) error {
+	cmd := exec.Command("tmux", "list-panes", "-a", "-F", "#{pane_id}\t#{pane_current_path}")
+	output, err := cmd.Output()
+	if err != nil {
+		// No tmux server running
+		return nil
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		paneID, panePath, ok := strings.Cut(line, "\t")
+		if !ok || !isWithin(panePath, path) {
+			continue
+		}
+		if output, err := exec.Command("tmux", "kill-pane", "-t", paneID).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to close tmux pane: %s", strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
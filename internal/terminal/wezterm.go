@@ -0,0 +1,80 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// wezterm opens worktrees through the WezTerm CLI
+type wezterm struct{}
+
+func init() {
+	Register("wezterm", wezterm{})
+}
+
+func (wezterm) Name() string {
+	return "WezTerm"
+}
+
+func (wezterm) IsAvailable() bool {
+	_, err := exec.LookPath("wezterm")
+	return err == nil
+}
+
+func (wezterm) Open(path string, mode OpenMode, execCmd string) error {
+	var args []string
+
+	switch mode {
+	case ModePane:
+		args = []string{"cli", "split-pane", "--cwd", path}
+	case ModeWindow:
+		args = []string{"cli", "spawn", "--new-window", "--cwd", path}
+	default:
+		args = []string{"cli", "spawn", "--cwd", path}
+	}
+
+	if execCmd != "" {
+		args = append(args, "--", "sh", "-c", execCmd)
+	}
+
+	cmd := exec.Command("wezterm", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to open WezTerm: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Close kills every pane whose working directory is inside the given path
+func (wezterm) Close(path string) error {
+	cmd := exec.Command("wezterm", "cli", "list", "--format", "json")
+	output, err := cmd.Output()
+	if err != nil {
+		// No WezTerm GUI running
+		return nil
+	}
+
+	var panes []struct {
+		PaneID int    `json:"pane_id"`
+		Cwd    string `json:"cwd"`
+	}
+	if err := json.Unmarshal(output, &panes); err != nil {
+		return fmt.Errorf("failed to parse WezTerm pane list: %w", err)
+	}
+
+	for _, pane := range panes {
+		// cwd is reported as a file:// URL
+		cwd, err := url.Parse(pane.Cwd)
+		if err != nil || !isWithin(cwd.Path, path) {
+			continue
+		}
+		kill := exec.Command("wezterm", "cli", "kill-pane", "--pane-id", strconv.Itoa(pane.PaneID))
+		if output, err := kill.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to close WezTerm pane: %s", strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
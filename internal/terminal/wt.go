@@ -3,30 +3,25 @@ package terminal
 import (
 	"fmt"
 	"os/exec"
-	"runtime"
 )
 
-// OpenMode represents how to open the terminal
-type OpenMode string
+// windowsTerminal opens worktrees in Windows Terminal (wt.exe)
+type windowsTerminal struct{}
 
-const (
-	ModeTab    OpenMode = "tab"
-	ModePane   OpenMode = "pane"
-	ModeWindow OpenMode = "window"
-)
+func init() {
+	Register("wt", windowsTerminal{})
+}
 
-// OpenInTerminal opens the given path in a terminal
-// Windows: Windows Terminal (wt.exe)
-// macOS/Linux: tmux
-func OpenInTerminal(path string, mode OpenMode, execCmd string) error {
-	if runtime.GOOS == "windows" {
-		return openWindowsTerminal(path, mode, execCmd)
-	}
-	return openTmux(path, mode, execCmd)
+func (windowsTerminal) Name() string {
+	return "Windows Terminal"
 }
 
-// openWindowsTerminal opens Windows Terminal
-func openWindowsTerminal(path string, mode OpenMode, execCmd string) error {
+func (windowsTerminal) IsAvailable() bool {
+	_, err := exec.LookPath("wt.exe")
+	return err == nil
+}
+
+func (windowsTerminal) Open(path string, mode OpenMode, execCmd string) error {
 	var args []string
 
 	switch mode {
@@ -49,42 +44,7 @@ func openWindowsTerminal(path string, mode OpenMode, execCmd string) error {
 	return nil
 }
 
-// openTmux opens tmux pane/window
-func openTmux(path string, mode OpenMode, execCmd string) error {
-	var args []string
-
-	switch mode {
-	case ModePane:
-		args = []string{"split-window", "-c", path}
-	default:
-		args = []string{"new-window", "-c", path}
-	}
-
-	if execCmd != "" {
-		args = append(args, execCmd)
-	}
-
-	cmd := exec.Command("tmux", args...)
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to open tmux: %w", err)
-	}
+// Close is not supported: wt.exe has no way to address existing tabs
+func (windowsTerminal) Close(path string) error {
 	return nil
 }
-
-// IsAvailable checks if terminal is available
-func IsAvailable() bool {
-	if runtime.GOOS == "windows" {
-		_, err := exec.LookPath("wt.exe")
-		return err == nil
-	}
-	_, err := exec.LookPath("tmux")
-	return err == nil
-}
-
-// TerminalName returns the name of the terminal being used
-func TerminalName() string {
-	if runtime.GOOS == "windows" {
-		return "Windows Terminal"
-	}
-	return "tmux"
-}
@@ -0,0 +1,51 @@
+package terminal
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// zellij opens worktrees in the current zellij session
+type zellij struct{}
+
+func init() {
+	Register("zellij", zellij{})
+}
+
+func (zellij) Name() string {
+	return "zellij"
+}
+
+func (zellij) IsAvailable() bool {
+	_, err := exec.LookPath("zellij")
+	return err == nil
+}
+
+func (zellij) Open(path string, mode OpenMode, execCmd string) error {
+	var args []string
+
+	switch mode {
+	case ModePane:
+		args = []string{"action", "new-pane", "--cwd", path}
+	default:
+		// zellij has no separate OS windows, so window mode opens a tab
+		args = []string{"action", "new-tab", "--cwd", path}
+	}
+
+	if err := exec.Command("zellij", args...).Run(); err != nil {
+		return fmt.Errorf("failed to open zellij: %w", err)
+	}
+
+	// The new pane has focus, so type the command into its shell
+	if execCmd != "" {
+		if err := exec.Command("zellij", "action", "write-chars", execCmd+"\n").Run(); err != nil {
+			return fmt.Errorf("failed to run command in zellij: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close is not supported: zellij actions cannot target panes by directory
+func (zellij) Close(path string) error {
+	return nil
+}
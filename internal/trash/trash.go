@@ -0,0 +1,207 @@
+package trash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/satoruhiga/wtree/internal/config"
+	"github.com/satoruhiga/wtree/internal/fsutil"
+	"github.com/satoruhiga/wtree/internal/git"
+	"github.com/satoruhiga/wtree/internal/session"
+)
+
+const (
+	entryFile = "entry.json"
+	filesDir  = "files"
+)
+
+// Entry is a removed worktree that can be restored. The commits it needs are
+// kept alive by BranchRef and ChangesRef.
+type Entry struct {
+	Session   *session.Session `json:"session"`
+	Head      string           `json:"head,omitempty"`    // Commit the branch pointed to
+	Changes   string           `json:"changes,omitempty"` // Stash commit with uncommitted changes to tracked files
+	Files     []string         `json:"files,omitempty"`   // Saved untracked files, relative to the worktree
+	TrashedAt time.Time        `json:"trashed_at"`
+}
+
+// BranchRef returns the ref that keeps the branch head of a trashed worktree
+func BranchRef(id string) string {
+	return "refs/wtree/trash/" + id
+}
+
+// ChangesRef returns the ref that keeps the uncommitted changes of a trashed worktree
+func ChangesRef(id string) string {
+	return "refs/wtree/changes/" + id
+}
+
+// Dir returns the trash directory of a repository
+func Dir(repoRoot string) string {
+	return filepath.Join(repoRoot, ".wtree", "trash")
+}
+
+// entryDir returns the directory of one trashed worktree
+func entryDir(repoRoot, id string) string {
+	return filepath.Join(Dir(repoRoot), id)
+}
+
+// Put moves a session into the trash before its worktree and branch are
+// removed. The branch head and any uncommitted changes to tracked files are
+// kept as refs; files (paths relative to the worktree) are copied. It returns
+// nil if there is nothing to keep, i.e. neither the branch nor the worktree exists.
+func Put(repoRoot string, sess *session.Session, worktreeExists bool, files []string) (*Entry, error) {
+	entry := &Entry{Session: sess, TrashedAt: time.Now()}
+
+	if head, err := git.RevParse("refs/heads/" + sess.Branch); err == nil {
+		entry.Head = head
+	} else if worktreeExists {
+		// Detached or missing branch: keep whatever is checked out
+		if head, err := git.WorktreeHead(sess.AbsPath); err == nil {
+			entry.Head = head
+		}
+	}
+	if worktreeExists {
+		changes, err := git.StashCreate(sess.AbsPath)
+		if err != nil {
+			return nil, err
+		}
+		entry.Changes = changes
+	}
+	if entry.Head == "" && entry.Changes == "" {
+		return nil, nil
+	}
+
+	dir := entryDir(repoRoot, sess.ID)
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("failed to clear trash entry: %w", err)
+	}
+	// Saved files may include secrets such as .env, which must not be committed
+	if err := config.WriteIgnoreFile(repoRoot); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create trash directory: %w", err)
+	}
+
+	if entry.Head != "" {
+		if err := git.UpdateRef(BranchRef(sess.ID), entry.Head, "wtree: trash "+sess.ID); err != nil {
+			return nil, err
+		}
+	}
+	if entry.Changes != "" {
+		if err := git.UpdateRef(ChangesRef(sess.ID), entry.Changes, "wtree: trash "+sess.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, file := range files {
+		src := filepath.Join(sess.AbsPath, file)
+		if _, err := os.Lstat(src); err != nil {
+			continue
+		}
+		if err := fsutil.CopyPath(src, filepath.Join(dir, filesDir, file)); err != nil {
+			return nil, fmt.Errorf("failed to save %s: %w", file, err)
+		}
+		entry.Files = append(entry.Files, file)
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode trash entry: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, entryFile), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write trash entry: %w", err)
+	}
+	return entry, nil
+}
+
+// List returns all trashed worktrees, most recently removed first
+func List(repoRoot string) ([]*Entry, error) {
+	dirs, err := os.ReadDir(Dir(repoRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	var entries []*Entry
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(Dir(repoRoot), d.Name(), entryFile))
+		if err != nil {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil || entry.Session == nil {
+			continue
+		}
+		entries = append(entries, &entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].TrashedAt.After(entries[j].TrashedAt)
+	})
+	return entries, nil
+}
+
+// Find returns the trashed worktree matching a partial ID or name, like
+// session.Store.FindByPartialID
+func Find(repoRoot, partialID string) (*Entry, error) {
+	entries, err := List(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*Entry
+	for _, entry := range entries {
+		sess := entry.Session
+		if sess.ID == partialID || (sess.Name != "" && sess.Name == partialID) {
+			return entry, nil
+		}
+		if strings.HasPrefix(sess.ID, partialID) || (sess.Name != "" && strings.HasPrefix(sess.Name, partialID)) {
+			matches = append(matches, entry)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no trashed worktree found: %s", partialID)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("ambiguous ID '%s': matches %d trashed worktrees", partialID, len(matches))
+	}
+}
+
+// RestoreFiles copies the saved files of an entry into the worktree at dst
+func RestoreFiles(repoRoot string, entry *Entry, dst string) error {
+	for _, file := range entry.Files {
+		src := filepath.Join(entryDir(repoRoot, entry.Session.ID), filesDir, file)
+		if err := fsutil.CopyPath(src, filepath.Join(dst, file)); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// Delete permanently removes a trashed worktree and the refs that kept its commits
+func Delete(repoRoot, id string) error {
+	if err := git.DeleteRef(BranchRef(id)); err != nil {
+		return err
+	}
+	if err := git.DeleteRef(ChangesRef(id)); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(entryDir(repoRoot, id)); err != nil {
+		return fmt.Errorf("failed to remove trash entry: %w", err)
+	}
+	return nil
+}
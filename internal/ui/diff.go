@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// diffContext is the number of unchanged lines shown around changes
+const diffContext = 3
+
+// PrintDiff prints a unified diff between two versions of a file
+func PrintDiff(name, before, after string) {
+	a := splitLines(before)
+	b := splitLines(after)
+
+	// Longest common subsequence table, from the end
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	// Walk the table into an edit script of ' ', '-' and '+' lines
+	type edit struct {
+		op   byte
+		text string
+	}
+	var edits []edit
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, edit{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{'-', a[i]})
+			i++
+		default:
+			edits = append(edits, edit{'+', b[j]})
+			j++
+		}
+	}
+
+	bold := color.New(color.Bold).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+
+	fmt.Println(bold("--- " + name))
+	fmt.Println(bold("+++ " + name))
+
+	// Print changed lines with context, separating distant hunks
+	last := -1
+	for k, e := range edits {
+		near := false
+		for d := max(0, k-diffContext); d <= min(len(edits)-1, k+diffContext); d++ {
+			if edits[d].op != ' ' {
+				near = true
+				break
+			}
+		}
+		if !near {
+			continue
+		}
+		if last != k-1 {
+			fmt.Println(cyan("@@"))
+		}
+		last = k
+		switch e.op {
+		case '-':
+			fmt.Println(red("-" + e.text))
+		case '+':
+			fmt.Println(green("+" + e.text))
+		default:
+			fmt.Println(" " + e.text)
+		}
+	}
+}
+
+// splitLines splits text into lines without the trailing newline
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,203 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// Output formats accepted by --format flags.
+// Any other value containing "{{" is treated as a Go template.
+const (
+	FormatTable  = "table"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatYAML   = "yaml"
+)
+
+// IsTemplate reports whether format is a Go template rather than a named format
+func IsTemplate(format string) bool {
+	return strings.Contains(format, "{{")
+}
+
+// ValidateFormat checks that format is a known format or a parsable template
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatTable, FormatJSON, FormatNDJSON, FormatYAML:
+		return nil
+	}
+	if IsTemplate(format) {
+		_, err := template.New("format").Parse(format)
+		if err != nil {
+			return fmt.Errorf("invalid format template: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown format '%s' (use table, json, ndjson, yaml or a Go template)", format)
+}
+
+// PrintFormatted writes items to stdout in a machine-readable format.
+// FormatTable is not handled here; callers render their own tables.
+func PrintFormatted[T any](format string, items []T) error {
+	return WriteFormatted(os.Stdout, format, items)
+}
+
+// WriteFormatted writes items to w in a machine-readable format
+func WriteFormatted[T any](w io.Writer, format string, items []T) error {
+	if items == nil {
+		items = []T{}
+	}
+
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, item := range items {
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatYAML:
+		data, err := json.Marshal(items)
+		if err != nil {
+			return err
+		}
+		return jsonToYAML(w, data)
+	}
+
+	if IsTemplate(format) {
+		tmpl, err := template.New("format").Parse(format)
+		if err != nil {
+			return fmt.Errorf("invalid format template: %w", err)
+		}
+		for _, item := range items {
+			if err := tmpl.Execute(w, item); err != nil {
+				return err
+			}
+			fmt.Fprintln(w)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown format '%s'", format)
+}
+
+// jsonToYAML re-encodes a JSON document as block-style YAML, keeping key order.
+// Strings are emitted as JSON-quoted scalars, which YAML accepts verbatim.
+func jsonToYAML(w io.Writer, data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var buf bytes.Buffer
+	if err := writeYAMLValue(&buf, dec, 0, yamlTop); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// yamlContext describes what precedes a value on the current line
+type yamlContext int
+
+const (
+	yamlTop  yamlContext = iota // start of document
+	yamlKey                     // after "key:"
+	yamlItem                    // after "-"
+)
+
+// writeYAMLValue writes the next JSON value from dec at the given indent level
+func writeYAMLValue(buf *bytes.Buffer, dec *json.Decoder, indent int, ctx yamlContext) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	sep := " "
+	if ctx == yamlTop {
+		sep = ""
+	}
+	pad := strings.Repeat("  ", indent)
+
+	switch t := tok.(type) {
+	case json.Delim:
+		empty := !dec.More()
+		if empty {
+			if _, err := dec.Token(); err != nil {
+				return err
+			}
+			if t == '{' {
+				buf.WriteString(sep + "{}\n")
+			} else {
+				buf.WriteString(sep + "[]\n")
+			}
+			return nil
+		}
+
+		// Nested blocks start on the next line, except that the first key of
+		// an object in a list shares the "-" line
+		first := true
+		linePad := func() string {
+			if first && ctx == yamlItem {
+				return " "
+			}
+			return pad
+		}
+		if ctx == yamlKey {
+			buf.WriteString("\n")
+		}
+
+		for dec.More() {
+			if t == '{' {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(buf, "%s%s:", linePad(), formatYAMLKey(keyTok.(string)))
+				first = false
+				if err := writeYAMLValue(buf, dec, indent+1, yamlKey); err != nil {
+					return err
+				}
+			} else {
+				fmt.Fprintf(buf, "%s-", linePad())
+				first = false
+				if err := writeYAMLValue(buf, dec, indent+1, yamlItem); err != nil {
+					return err
+				}
+			}
+		}
+		_, err := dec.Token()
+		return err
+	case string:
+		quoted, _ := json.Marshal(t)
+		buf.WriteString(sep + string(quoted) + "\n")
+	case nil:
+		buf.WriteString(sep + "null\n")
+	default:
+		buf.WriteString(sep + fmt.Sprint(t) + "\n")
+	}
+	return nil
+}
+
+// formatYAMLKey returns key unquoted when it is a plain identifier
+func formatYAMLKey(key string) string {
+	plain := key != ""
+	for _, r := range key {
+		if !(r == '_' || r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return key
+	}
+	quoted, _ := json.Marshal(key)
+	return string(quoted)
+}
@@ -0,0 +1,82 @@
+package verify
+
+import (
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/satoruhiga/wtree/internal/session"
+	"github.com/satoruhiga/wtree/internal/shell"
+)
+
+// tailLines is the number of output lines kept for each command
+const tailLines = 20
+
+// Run runs commands in dir in order, stopping at the first failure.
+// Output is streamed to out and the tail of each command's output is recorded.
+func Run(dir string, commands []string, out io.Writer) *session.Verification {
+	result := &session.Verification{
+		Passed: true,
+		RunAt:  time.Now(),
+		Steps:  []session.VerifyStep{},
+	}
+
+	for _, cmdStr := range commands {
+		tail := &tailBuffer{}
+		cmd := shell.Command(cmdStr)
+		cmd.Dir = dir
+		cmd.Stdout = io.MultiWriter(out, tail)
+		cmd.Stderr = cmd.Stdout
+
+		start := time.Now()
+		err := cmd.Run()
+		step := session.VerifyStep{
+			Command:    cmdStr,
+			Duration:   time.Since(start),
+			OutputTail: tail.String(),
+		}
+		if err != nil {
+			step.ExitCode = -1
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				step.ExitCode = exitErr.ExitCode()
+			}
+		}
+
+		result.Steps = append(result.Steps, step)
+		if err != nil {
+			result.Passed = false
+			break
+		}
+	}
+	return result
+}
+
+// tailBuffer keeps the last tailLines lines written to it
+type tailBuffer struct {
+	lines   []string
+	partial string
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	parts := strings.Split(t.partial+string(p), "\n")
+	t.partial = parts[len(parts)-1]
+	t.lines = append(t.lines, parts[:len(parts)-1]...)
+	if len(t.lines) > tailLines {
+		t.lines = t.lines[len(t.lines)-tailLines:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	lines := t.lines
+	if t.partial != "" {
+		lines = append(lines, t.partial)
+	}
+	if len(lines) > tailLines {
+		lines = lines[len(lines)-tailLines:]
+	}
+	return strings.Join(lines, "\n")
+}